@@ -1,25 +1,51 @@
 package main
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
 
 	"github.com/crossmint/megaverse-challenge/internal/application"
+	"github.com/crossmint/megaverse-challenge/internal/application/strategies"
+	"github.com/crossmint/megaverse-challenge/internal/domain"
 	"github.com/crossmint/megaverse-challenge/internal/infrastructure/api"
 	cfgpkg "github.com/crossmint/megaverse-challenge/internal/infrastructure/config"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/goalcache"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/logging"
 	"github.com/crossmint/megaverse-challenge/internal/interfaces/cli"
+	"github.com/crossmint/megaverse-challenge/pkg/audit"
+	"github.com/crossmint/megaverse-challenge/pkg/correlation"
+	"github.com/crossmint/megaverse-challenge/pkg/metrics"
 	"github.com/crossmint/megaverse-challenge/pkg/ratelimit"
+	"github.com/crossmint/megaverse-challenge/pkg/tracing"
 )
 
+const serviceName = "megaverse"
+
 func main() {
-	deps := &cli.Dependencies{}
-	defaultConfigPath := "config/config.yaml"
+	deps := &cli.Dependencies{
+		ConfigPath: "config/config.yaml",
+		Metrics:    metrics.NewRegistry(),
+		Setup:      setup,
+	}
+
+	rootCmd := cli.NewRootCommand(deps)
+
+	err := rootCmd.Execute()
+	deps.Close()
+	if err != nil {
+		fatal(err)
+	}
+}
 
+// setup loads configuration and wires the services once command line flags have been parsed
+func setup(deps *cli.Dependencies) error {
 	// Attempt to load configuration; log but don't exit if missing (init command handles writing it)
-	if _, err := os.Stat(defaultConfigPath); err == nil {
-		cfg, err := cfgpkg.LoadFromFile(defaultConfigPath)
+	if _, err := os.Stat(deps.ConfigPath); err == nil {
+		cfg, err := cfgpkg.LoadFromFile(deps.ConfigPath)
 		if err != nil {
-			log.Printf("warning: failed to load configuration: %v", err)
+			slog.Warn("failed to load configuration", "error", err)
 		} else {
 			deps.Config = cfg
 		}
@@ -27,34 +53,174 @@ func main() {
 		// Fall back to default config for init command
 		deps.Config = cfgpkg.DefaultConfig()
 	}
-	deps.ConfigPath = defaultConfigPath
 
-	if deps.Config != nil && deps.Config.API.CandidateID != "" {
-		retryCfg := deps.Config.API.RetryConfig.ToRetryConfig()
-		client := api.NewClient(api.ClientConfig{
-			BaseURL:           deps.Config.API.BaseURL,
-			CandidateID:       deps.Config.API.CandidateID,
-			Timeout:           deps.Config.API.Timeout,
-			RetryConfig:       retryCfg,
-			RequestsPerSecond: deps.Config.API.RateLimitConfig.RequestsPerSecond,
+	if deps.Config == nil {
+		return nil
+	}
+
+	logger, err := logging.New(deps.LogWriter(), deps.Config.Logging.Level, deps.Config.Logging.Format)
+	if err != nil {
+		return err
+	}
+	logger = slog.New(correlation.NewHandler(logger.Handler(), deps.RunID))
+	slog.SetDefault(logger)
+	deps.Logger = logger
+
+	if deps.TraceFile != "" {
+		exporter, err := tracing.NewFileExporter(deps.TraceFile, serviceName)
+		if err != nil {
+			return err
+		}
+		deps.Tracer = tracing.NewTracer(serviceName, exporter, func(err error) {
+			logger.Warn("tracing export failed", "error", err)
+		})
+		deps.OnClose(func() {
+			if err := exporter.Close(); err != nil {
+				logger.Warn("failed to close trace file", "error", err)
+			}
 		})
+	}
 
-		repository := api.NewRepository(client)
-		deps.Repository = repository
+	if deps.Config.API.CandidateID == "" {
+		return nil
+	}
 
-		logger := log.New(os.Stdout, "[megaverse] ", log.LstdFlags)
-		rps := deps.Config.API.RateLimitConfig.RequestsPerSecond
-		if rps <= 0 {
-			rps = 2.0
+	var auditLog *audit.Log
+	if path := deps.Config.Audit.Path; path != "" {
+		var err error
+		auditLog, err = audit.Open(path)
+		if err != nil {
+			logger.Warn("failed to open audit log; requests will not be audited", "path", path, "error", err)
+		} else {
+			deps.OnClose(func() {
+				if err := auditLog.Close(); err != nil {
+					logger.Warn("failed to close audit log", "error", err)
+				}
+			})
 		}
-		limiter := ratelimit.NewLimiter(rps)
+	}
 
-		deps.Service = application.NewMegaverseService(repository, logger, limiter)
+	retryCfg := deps.Config.API.RetryConfig.ToRetryConfig()
+	transport := api.TransportConfig{
+		MaxIdleConnsPerHost: deps.Config.API.Transport.MaxIdleConnsPerHost,
+		IdleConnTimeout:     deps.Config.API.Transport.IdleConnTimeout,
+		TLSHandshakeTimeout: deps.Config.API.Transport.TLSHandshakeTimeout,
+		KeepAlive:           deps.Config.API.Transport.KeepAlive,
+		DisableHTTP2:        !deps.Config.API.Transport.HTTP2,
+	}
+	if err := configureTLS(&transport, deps.Config.API, logger); err != nil {
+		return err
 	}
+	client := api.NewClient(api.ClientConfig{
+		BaseURLs:          deps.Config.API.ResolvedBaseURLs(),
+		Failover:          api.FailoverConfig{Failures: deps.Config.API.Failover.Failures, Cooldown: deps.Config.API.Failover.Cooldown},
+		CandidateID:       deps.Config.API.CandidateID,
+		Timeout:           deps.Config.API.Timeout,
+		AttemptTimeout:    deps.Config.API.AttemptTimeout,
+		Transport:         transport,
+		RetryConfig:       retryCfg,
+		RequestsPerSecond: deps.Config.API.RateLimitConfig.RequestsPerSecond,
+		Logger:            deps.Logger,
+		Metrics:           deps.Metrics,
+		Tracer:            deps.Tracer,
+		Observer:          deps.ObserveRequest,
+		AuditLog:          auditLog,
+		CircuitBreaker:    deps.Config.API.CircuitBreaker.ToBreakerConfig(),
+		OnCircuitChange:   deps.ObserveCircuit,
+		DebugHTTP:         deps.DebugHTTP,
+		DebugBodyLimit:    deps.Config.Logging.HTTPBodyLimit,
+	})
 
-	rootCmd := cli.NewRootCommand(deps)
+	endpoints := apiEndpoints(deps.Config.API.Endpoints).WithDefaults()
+	if err := endpoints.Validate(); err != nil {
+		return fmt.Errorf("invalid api.endpoints: %w", err)
+	}
+	repository := api.NewRepository(client).WithEndpoints(endpoints).WithOffline(deps.Offline)
+	if dir := deps.Config.Cache.Dir; dir != "" {
+		repository.WithGoalCache(goalcache.New(dir), deps.Config.Cache.GoalTTL)
+	}
+	deps.Repository = repository
+
+	rps := deps.Config.API.RateLimitConfig.RequestsPerSecond
+	if rps <= 0 {
+		rps = 2.0
+	}
+	limiter := ratelimit.NewLimiter(rps)
+
+	options := application.ExecutionOptions{
+		MaxWorkers:    deps.Config.Execution.MaxWorkers,
+		BatchSize:     deps.Config.Execution.BatchSize,
+		BatchCooldown: deps.Config.Execution.BatchCooldown,
+		VerifyBatches: deps.Config.Execution.VerifyBatches,
+	}
+	if deps.Config.Execution.Order != "" {
+		order, err := strategies.ParseExecutionOrder(deps.Config.Execution.Order)
+		if err != nil {
+			return err
+		}
+		options.Order = &order
+	}
+
+	deps.Service = application.NewMegaverseService(repository, deps.Logger, limiter).
+		WithExecutionOptions(options).
+		WithMetrics(deps.Metrics).
+		WithTracer(deps.Tracer)
+
+	return nil
+}
+
+// configureTLS applies the proxy and TLS settings of config to transport
+func configureTLS(transport *api.TransportConfig, config cfgpkg.APIConfig, logger *slog.Logger) error {
+	if config.ProxyURL != "" {
+		proxy, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid api.proxy_url: %w", err)
+		}
+		transport.Proxy = proxy
+	}
+
+	tlsConfig, err := api.TLSFiles{
+		CAFile:             config.CAFile,
+		CertFile:           config.CertFile,
+		KeyFile:            config.KeyFile,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}.Load()
+	if err != nil {
+		return err
+	}
+	transport.TLS = tlsConfig
+
+	if config.InsecureSkipVerify {
+		const warning = "TLS CERTIFICATE VERIFICATION IS DISABLED: api.insecure_skip_verify accepts any server certificate, " +
+			"so anyone on the network path can read and alter API traffic, including the candidate ID. " +
+			"Use api.ca_file to trust an intercepting proxy instead."
+		// Printed as well as logged so a quiet logging.level cannot hide it
+		fmt.Fprintln(os.Stderr, "WARNING:", warning)
+		logger.Warn(warning)
+	}
+	return nil
+}
+
+// apiEndpoints converts the endpoint templates of the configuration to the API client format
+func apiEndpoints(config cfgpkg.EndpointsConfig) api.Endpoints {
+	endpoint := func(e cfgpkg.EndpointConfig) api.Endpoint {
+		return api.Endpoint{Method: e.Method, Path: e.Path, Body: e.Body}
+	}
+
+	endpoints := api.Endpoints{GoalMap: config.GoalMap, CurrentMap: config.CurrentMap}
+	for objectType, object := range config.Objects {
+		if endpoints.Objects == nil {
+			endpoints.Objects = make(map[string]api.ObjectEndpoints)
+		}
+		endpoints.Objects[objectType] = api.ObjectEndpoints{Create: endpoint(object.Create), Delete: endpoint(object.Delete)}
+	}
+	return endpoints
+}
 
-	if err := rootCmd.Execute(); err != nil {
-		log.Fatal(err)
+func fatal(err error) {
+	slog.Error(err.Error())
+	if hint := domain.Remediation(err); hint != "" {
+		fmt.Fprintln(os.Stderr, "hint:", hint)
 	}
+	os.Exit(1)
 }
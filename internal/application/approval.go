@@ -0,0 +1,109 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/operations"
+)
+
+// Answer is an operator's response to a proposed change in interactive mode
+type Answer int
+
+const (
+	// AnswerYes applies this change
+	AnswerYes Answer = iota
+
+	// AnswerNo skips this change
+	AnswerNo
+
+	// AnswerAll applies this change and every remaining one without asking
+	AnswerAll
+
+	// AnswerSkipType skips this change and every remaining change for the same object type
+	AnswerSkipType
+
+	// AnswerQuit skips this change and stops dispatching further changes
+	AnswerQuit
+)
+
+// String returns the answer as shown in prompts and reports
+func (a Answer) String() string {
+	switch a {
+	case AnswerYes:
+		return "yes"
+	case AnswerNo:
+		return "no"
+	case AnswerAll:
+		return "all"
+	case AnswerSkipType:
+		return "skip-type"
+	case AnswerQuit:
+		return "quit"
+	default:
+		return fmt.Sprintf("Answer(%d)", int(a))
+	}
+}
+
+// Approver asks an operator whether an operation may be applied
+type Approver interface {
+	Approve(ctx context.Context, op operations.Operation) (Answer, error)
+}
+
+// approvalGate applies an Approver's answers for the duration of one run,
+// remembering "all", "skip-type", and "quit" so later changes are not prompted again.
+type approvalGate struct {
+	approver   Approver
+	report     *RunReport
+	approveAll bool
+	skipTypes  map[string]bool
+	quit       bool
+}
+
+func newApprovalGate(approver Approver, report *RunReport) *approvalGate {
+	return &approvalGate{
+		approver:  approver,
+		report:    report,
+		skipTypes: make(map[string]bool),
+	}
+}
+
+// allow reports whether op may be applied and whether dispatching should stop altogether
+func (g *approvalGate) allow(ctx context.Context, op operations.Operation) (apply bool, stop bool, err error) {
+	if g.quit {
+		return false, true, nil
+	}
+
+	objectType := op.Subject().GetType()
+
+	switch {
+	case g.approveAll:
+		g.report.recordDecision(op, AnswerAll, false)
+		return true, false, nil
+	case g.skipTypes[objectType]:
+		g.report.recordDecision(op, AnswerSkipType, false)
+		return false, false, nil
+	}
+
+	answer, err := g.approver.Approve(ctx, op)
+	if err != nil {
+		return false, true, fmt.Errorf("approval failed: %w", err)
+	}
+	g.report.recordDecision(op, answer, true)
+
+	switch answer {
+	case AnswerYes:
+		return true, false, nil
+	case AnswerAll:
+		g.approveAll = true
+		return true, false, nil
+	case AnswerSkipType:
+		g.skipTypes[objectType] = true
+		return false, false, nil
+	case AnswerQuit:
+		g.quit = true
+		return false, true, nil
+	default:
+		return false, false, nil
+	}
+}
@@ -0,0 +1,84 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/application/strategies"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/domain/operations"
+)
+
+// scriptedApprover answers prompts from a fixed script and remembers what it was asked
+type scriptedApprover struct {
+	answers []Answer
+	asked   []operations.Operation
+}
+
+func (a *scriptedApprover) Approve(_ context.Context, op operations.Operation) (Answer, error) {
+	a.asked = append(a.asked, op)
+	answer := a.answers[0]
+	a.answers = a.answers[1:]
+	return answer, nil
+}
+
+func TestInteractiveApprovalGatesDispatch(t *testing.T) {
+	repo := newFakeRepository(6, 1)
+	require.NoError(t, repo.world.PlaceObject(&entities.Soloon{Position: entities.Position{Row: 0, Column: 1}, Color: entities.RedSoloon}))
+	require.NoError(t, repo.world.PlaceObject(&entities.Polyanet{Position: entities.Position{Row: 0, Column: 5}}))
+
+	approver := &scriptedApprover{answers: []Answer{AnswerYes, AnswerYes, AnswerSkipType, AnswerQuit}}
+	service := NewMegaverseService(repo, nil, nil).WithApprover(approver)
+
+	cells := []entities.AstralObject{
+		&entities.Polyanet{Position: entities.Position{Row: 0, Column: 0}},
+		&entities.Polyanet{Position: entities.Position{Row: 0, Column: 1}}, // replaces the red soloon
+		&entities.Cometh{Position: entities.Position{Row: 0, Column: 2}, Direction: entities.UpCometh},
+		&entities.Cometh{Position: entities.Position{Row: 0, Column: 3}, Direction: entities.UpCometh},
+		&entities.Polyanet{Position: entities.Position{Row: 0, Column: 4}},
+		&entities.Polyanet{Position: entities.Position{Row: 0, Column: 5}}, // already present
+	}
+	strategy := strategies.NewGeneratedPatternStrategy("cells", 6, 1, func(_, col int) entities.AstralObject {
+		return cells[col]
+	})
+
+	report, err := service.ExecuteStrategy(context.Background(), strategy)
+	require.NoError(t, err)
+
+	require.Len(t, approver.asked, 4)
+	require.Equal(t, operations.Replace, approver.asked[1].Kind)
+	require.Equal(t, "SOLOON(red)", operations.Describe(approver.asked[1].Previous))
+
+	require.Equal(t, 2, report.Applied)
+	require.Len(t, report.Decisions, 5)
+	require.Equal(t, AnswerSkipType, report.Decisions[3].Answer)
+	require.False(t, report.Decisions[3].Prompted)
+	require.Equal(t, AnswerQuit, report.Decisions[4].Answer)
+
+	obj, err := repo.world.GetObject(0, 1)
+	require.NoError(t, err)
+	require.Equal(t, "POLYANET", obj.GetType())
+
+	obj, err = repo.world.GetObject(0, 2)
+	require.NoError(t, err)
+	require.Nil(t, obj)
+}
+
+func TestBatchedVerificationIgnoresDeclinedChanges(t *testing.T) {
+	repo := newFakeRepository(4, 1)
+	approver := &scriptedApprover{answers: []Answer{AnswerYes, AnswerNo, AnswerYes, AnswerNo}}
+
+	order := strategies.OrderBatched
+	service := NewMegaverseService(repo, nil, nil).WithApprover(approver).WithExecutionOptions(ExecutionOptions{
+		Order:         &order,
+		BatchSize:     2,
+		VerifyBatches: true,
+	})
+
+	report, err := service.ExecuteStrategy(context.Background(), rowStrategy(4))
+	require.NoError(t, err, "declined changes are not missing from the live map")
+	require.Equal(t, 2, report.Applied)
+	require.Equal(t, 2, report.Skipped)
+}
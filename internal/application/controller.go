@@ -0,0 +1,97 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+)
+
+// DesiredState loads the megaverse a controller should converge on
+type DesiredState func(ctx context.Context) (*entities.Megaverse, error)
+
+// ReconcileController keeps the live megaverse converged on a desired state.
+// It reconciles on start, on every interval tick, and whenever Trigger fires.
+type ReconcileController struct {
+	service  *MegaverseService
+	desired  DesiredState
+	interval time.Duration
+	trigger  <-chan struct{}
+
+	// PassTimeout bounds a single reconciliation pass; zero means no per-pass limit
+	PassTimeout time.Duration
+}
+
+// NewReconcileController creates a controller that reconciles every interval.
+// trigger may be nil; a closed trigger channel is ignored from then on.
+func NewReconcileController(service *MegaverseService, desired DesiredState, interval time.Duration, trigger <-chan struct{}) *ReconcileController {
+	return &ReconcileController{
+		service:  service,
+		desired:  desired,
+		interval: interval,
+		trigger:  trigger,
+	}
+}
+
+// Run reconciles until ctx is cancelled. Failed passes are logged and retried on the next trigger
+// rather than stopping the controller.
+func (c *ReconcileController) Run(ctx context.Context) error {
+	if c.interval <= 0 {
+		return fmt.Errorf("reconcile interval must be positive")
+	}
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	trigger := c.trigger
+	c.pass(ctx, "startup")
+
+	for {
+		var reason string
+
+		select {
+		case <-ctx.Done():
+			c.service.logger.InfoContext(ctx, "reconcile controller stopped")
+			return nil
+		case <-ticker.C:
+			reason = "interval"
+		case _, ok := <-trigger:
+			if !ok {
+				trigger = nil
+				continue
+			}
+			reason = "goal file changed"
+		}
+
+		c.pass(ctx, reason)
+	}
+}
+
+func (c *ReconcileController) pass(ctx context.Context, reason string) {
+	if c.PassTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.PassTimeout)
+		defer cancel()
+	}
+
+	c.service.logger.InfoContext(ctx, "reconciling", "reason", reason)
+
+	desired, err := c.desired(ctx)
+	if err != nil {
+		c.service.logger.ErrorContext(ctx, "failed to load desired state", "error", err)
+		return
+	}
+
+	report, err := c.service.Reconcile(ctx, desired)
+	if err != nil {
+		c.service.logger.ErrorContext(ctx, "reconcile pass failed", "error", err)
+		return
+	}
+
+	if len(report.Operations) == 0 {
+		c.service.logger.InfoContext(ctx, "no drift detected")
+		return
+	}
+	c.service.logger.InfoContext(ctx, "corrected drift", "cells", report.Applied, "failed", report.Failed)
+}
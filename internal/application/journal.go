@@ -0,0 +1,74 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/operations"
+	"github.com/crossmint/megaverse-challenge/pkg/correlation"
+)
+
+// WithJournal records run and object events to journal; nil disables journaling
+func (s *MegaverseService) WithJournal(journal domain.Journal) *MegaverseService {
+	s.journal = journal
+	return s
+}
+
+// withOperation tags ctx with a fresh operation ID so the logs, spans, journal entries and API
+// requests of one dispatch can be tied together
+func withOperation(ctx context.Context) context.Context {
+	return correlation.WithOperationID(ctx, correlation.NewOperationID())
+}
+
+// record stamps entry with the time and the correlation IDs carried by ctx and appends it to the journal.
+// A journal that cannot be written is logged rather than failing the run.
+func (s *MegaverseService) record(ctx context.Context, entry domain.JournalEntry) {
+	if s.journal == nil {
+		return
+	}
+
+	entry.Time = time.Now()
+	entry.RunID = correlation.RunID(ctx)
+	entry.OperationID = correlation.OperationID(ctx)
+
+	if err := s.journal.Append(entry); err != nil {
+		s.logger.WarnContext(ctx, "failed to write journal entry", "event", entry.Event, "error", err)
+	}
+}
+
+func (s *MegaverseService) recordRunStarted(ctx context.Context, report *RunReport, width, height int) {
+	s.record(ctx, domain.JournalEntry{Event: domain.JournalRunStarted, Name: report.Name, Width: width, Height: height})
+}
+
+func (s *MegaverseService) recordPlanGenerated(ctx context.Context, objects int) {
+	s.record(ctx, domain.JournalEntry{Event: domain.JournalPlanGenerated, Objects: objects})
+}
+
+func (s *MegaverseService) recordRunFinished(ctx context.Context, report *RunReport, err error) {
+	entry := domain.JournalEntry{Event: domain.JournalRunFinished, Name: report.Name}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	s.record(ctx, entry)
+}
+
+// recordOperation journals an event about op, including err when it failed
+func (s *MegaverseService) recordOperation(ctx context.Context, event string, op operations.Operation, err error) {
+	pos := op.Position
+	entry := domain.JournalEntry{
+		Event:     event,
+		Operation: op.Kind.String(),
+		Position:  &pos,
+	}
+	if op.Object != nil {
+		entry.Object = domain.GoalCellValue(op.Object)
+	}
+	if op.Previous != nil {
+		entry.Previous = domain.GoalCellValue(op.Previous)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	s.record(ctx, entry)
+}
@@ -0,0 +1,52 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/pkg/correlation"
+)
+
+type memoryJournal struct {
+	mu      sync.Mutex
+	entries []domain.JournalEntry
+}
+
+func (j *memoryJournal) Append(entry domain.JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, entry)
+	return nil
+}
+
+func TestJournalCarriesRunAndOperationIDs(t *testing.T) {
+	journal := &memoryJournal{}
+	service := NewMegaverseService(newFakeRepository(3, 1), nil, nil).WithJournal(journal)
+
+	ctx := correlation.WithRunID(context.Background(), "run-1")
+	report, err := service.ExecuteStrategy(ctx, rowStrategy(3))
+	require.NoError(t, err)
+	require.Equal(t, "run-1", report.RunID)
+
+	require.Equal(t, domain.JournalRunStarted, journal.entries[0].Event)
+	require.Equal(t, 3, journal.entries[0].Width)
+	require.Equal(t, domain.JournalRunFinished, journal.entries[len(journal.entries)-1].Event)
+
+	started := make(map[string]bool)
+	for _, entry := range journal.entries {
+		require.Equal(t, "run-1", entry.RunID)
+		switch entry.Event {
+		case domain.JournalObjectStarted:
+			require.NotEmpty(t, entry.OperationID)
+			require.Equal(t, "POLYANET", entry.Object)
+			started[entry.OperationID] = true
+		case domain.JournalObjectApplied:
+			require.True(t, started[entry.OperationID], "applied entry must share its dispatch's operation ID")
+		}
+	}
+	require.Len(t, started, 3)
+}
@@ -0,0 +1,49 @@
+package application
+
+import (
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/pkg/metrics"
+)
+
+// Object outcomes reported in megaverse_objects_total
+const (
+	outcomeCreated = "created"
+	outcomeDeleted = "deleted"
+	outcomeFailed  = "failed"
+	outcomeSkipped = "skipped"
+)
+
+// serviceMetrics holds the metric families the service reports to
+type serviceMetrics struct {
+	objects     *metrics.CounterVec
+	inFlight    *metrics.GaugeVec
+	limiterWait *metrics.HistogramVec
+}
+
+func newServiceMetrics(registry *metrics.Registry) serviceMetrics {
+	return serviceMetrics{
+		objects: registry.Counter("megaverse_objects_total",
+			"Astral objects processed, by type and outcome (created, deleted, failed, skipped).",
+			"type", "outcome"),
+		inFlight: registry.Gauge("megaverse_workers_in_flight",
+			"Workers currently dispatching an object to the API."),
+		limiterWait: registry.Histogram("megaverse_rate_limiter_wait_seconds",
+			"Time spent waiting on rate limiters before doing work.",
+			nil, "component"),
+	}
+}
+
+func (m serviceMetrics) observeObject(objectType, outcome string) {
+	m.objects.With(objectType, outcome).Inc()
+}
+
+func (m serviceMetrics) observeLimiterWait(d time.Duration) {
+	m.limiterWait.With("service").Observe(d.Seconds())
+}
+
+// WithMetrics reports object outcomes, in-flight workers, and limiter waits to registry
+func (s *MegaverseService) WithMetrics(registry *metrics.Registry) *MegaverseService {
+	s.metrics = newServiceMetrics(registry)
+	return s
+}
@@ -0,0 +1,173 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/domain/operations"
+	"github.com/crossmint/megaverse-challenge/pkg/tracing"
+)
+
+// Diff lists the operations needed to turn current into desired, in row-major order.
+// Cells outside the desired grid are treated as empty in the desired state.
+func Diff(current, desired *entities.Megaverse) []operations.Operation {
+	height := max(current.Height, desired.Height)
+	width := max(current.Width, desired.Width)
+
+	var ops []operations.Operation
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			pos := entities.Position{Row: row, Column: col}
+			if op, ok := operations.Between(pos, cellAt(current, row, col), cellAt(desired, row, col)); ok {
+				ops = append(ops, op)
+			}
+		}
+	}
+
+	return ops
+}
+
+func cellAt(m *entities.Megaverse, row, col int) entities.AstralObject {
+	obj, err := m.GetObject(row, col)
+	if err != nil {
+		return nil
+	}
+	return obj
+}
+
+// Reconcile compares the live megaverse with desired and corrects every drifted cell.
+// The report lists the drift found and how much of it was corrected.
+func (s *MegaverseService) Reconcile(ctx context.Context, desired *entities.Megaverse) (report *RunReport, err error) {
+	report = newRunReport(ctx, "Reconcile")
+
+	ctx, span := s.tracer.Start(ctx, "reconcile.run", tracing.KindInternal)
+	defer func() {
+		report.finish()
+		s.recordRunFinished(ctx, report, err)
+		finishRunSpan(span, report, err)
+	}()
+	s.recordRunStarted(ctx, report, desired.Width, desired.Height)
+
+	if err := s.waitForRateLimit(ctx); err != nil {
+		return report, err
+	}
+
+	current, err := s.repository.GetCurrentMap(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to fetch current map: %w", err)
+	}
+
+	ops := Diff(current, desired)
+	s.recordPlanGenerated(ctx, len(ops))
+	if len(ops) == 0 {
+		return report, nil
+	}
+
+	s.logger.InfoContext(ctx, "detected drift", "cells", len(ops))
+
+	return report, s.applyOperations(ctx, report, ops)
+}
+
+// applyOperations optimises ops into the minimal equivalent list and applies it in order, asking the
+// approver first when one is configured. Callers may hand over raw lists with redundant work.
+func (s *MegaverseService) applyOperations(ctx context.Context, report *RunReport, ops []operations.Operation) error {
+	optimized := operations.Optimize(ops)
+	if removed := len(ops) - len(optimized); removed > 0 {
+		s.logger.InfoContext(ctx, "optimised away redundant operations", "removed", removed)
+	}
+	ops = optimized
+
+	report.Operations = ops
+	report.Planned = len(ops)
+
+	var gate *approvalGate
+	if s.approver != nil {
+		gate = newApprovalGate(s.approver, report)
+	}
+
+	for _, op := range ops {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context cancelled: %w", err)
+		}
+
+		if gate != nil {
+			apply, stop, err := gate.allow(ctx, op)
+			if err != nil {
+				return err
+			}
+			if !apply {
+				report.recordSkipped()
+				s.metrics.observeObject(op.Subject().GetType(), outcomeSkipped)
+				if stop {
+					break
+				}
+				continue
+			}
+		}
+
+		s.metrics.inFlight.With().Inc()
+		opCtx, span := s.tracer.Start(withOperation(ctx), "operation.apply", tracing.KindInternal, operationAttributes(op)...)
+		s.recordOperation(opCtx, domain.JournalObjectStarted, op, nil)
+		err := s.applyOperation(opCtx, op)
+		span.RecordError(err)
+		span.Finish()
+		s.metrics.inFlight.With().Dec()
+		if err != nil {
+			s.logger.ErrorContext(opCtx, "failed to apply operation", append(operationFields(op), "error", err)...)
+			s.recordOperation(opCtx, domain.JournalObjectFailed, op, err)
+			report.recordFailed()
+			s.metrics.observeObject(op.Subject().GetType(), outcomeFailed)
+			continue
+		}
+
+		s.logger.InfoContext(opCtx, "applied operation", operationFields(op)...)
+		s.recordOperation(opCtx, domain.JournalObjectApplied, op, nil)
+		report.recordApplied()
+		outcome := outcomeCreated
+		if op.Kind == operations.Delete {
+			outcome = outcomeDeleted
+		}
+		s.metrics.observeObject(op.Subject().GetType(), outcome)
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("encountered %d errors while applying operations", report.Failed)
+	}
+
+	return nil
+}
+
+// applyOperation performs the API calls for a single operation. A replace is a delete followed by a create.
+func (s *MegaverseService) applyOperation(ctx context.Context, op operations.Operation) error {
+	if op.Previous != nil {
+		if err := s.waitForRateLimit(ctx); err != nil {
+			return err
+		}
+		if err := s.repository.DeleteObject(ctx, op.Previous.GetType(), op.Position); err != nil {
+			return err
+		}
+	}
+
+	if op.Object != nil {
+		if err := s.waitForRateLimit(ctx); err != nil {
+			return err
+		}
+		return s.createObject(ctx, op.Object)
+	}
+
+	return nil
+}
+
+// operationFields returns the structured log fields describing op
+func operationFields(op operations.Operation) []any {
+	return []any{
+		"operation", op.Kind.String(),
+		"type", op.Subject().GetType(),
+		"row", op.Position.Row,
+		"column", op.Position.Column,
+		"current", operations.Describe(op.Previous),
+		"desired", operations.Describe(op.Object),
+	}
+}
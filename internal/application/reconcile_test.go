@@ -0,0 +1,125 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/domain/operations"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/goalfile"
+)
+
+func TestDiffClassifiesDrift(t *testing.T) {
+	current := entities.NewMegaverse(3, 1)
+	require.NoError(t, current.PlaceObject(&entities.Polyanet{Position: entities.Position{Row: 0, Column: 1}}))
+	require.NoError(t, current.PlaceObject(&entities.Soloon{Position: entities.Position{Row: 0, Column: 2}, Color: entities.RedSoloon}))
+
+	goal := &domain.GoalMap{Goal: [][]string{{"POLYANET", "SPACE", "BLUE_SOLOON"}}}
+	desired, err := goal.ToMegaverse()
+	require.NoError(t, err)
+
+	ops := Diff(current, desired)
+	require.Len(t, ops, 3)
+
+	require.Equal(t, operations.Create, ops[0].Kind)
+	require.Equal(t, entities.Position{Row: 0, Column: 0}, ops[0].Position)
+
+	require.Equal(t, operations.Delete, ops[1].Kind)
+	require.Equal(t, "POLYANET", ops[1].Previous.GetType())
+
+	require.Equal(t, operations.Replace, ops[2].Kind)
+	require.Equal(t, "SOLOON(blue)", operations.Describe(ops[2].Object))
+}
+
+func TestReconcileConvergesOnDesiredState(t *testing.T) {
+	repo := newFakeRepository(2, 2)
+	require.NoError(t, repo.world.PlaceObject(&entities.Cometh{Position: entities.Position{Row: 1, Column: 1}, Direction: entities.UpCometh}))
+
+	goal := &domain.GoalMap{Goal: [][]string{
+		{"POLYANET", "SPACE"},
+		{"SPACE", "LEFT_COMETH"},
+	}}
+	desired, err := goal.ToMegaverse()
+	require.NoError(t, err)
+
+	service := NewMegaverseService(repo, nil, nil)
+
+	report, err := service.Reconcile(context.Background(), desired)
+	require.NoError(t, err)
+	require.Len(t, report.Operations, 2)
+	require.Equal(t, 2, report.Applied)
+
+	report, err = service.Reconcile(context.Background(), desired)
+	require.NoError(t, err)
+	require.Empty(t, report.Operations)
+}
+
+func TestApplyOperationsDispatchesOptimisedList(t *testing.T) {
+	repo := newFakeRepository(2, 1)
+	origin := entities.Position{Row: 0, Column: 0}
+	neighbour := entities.Position{Row: 0, Column: 1}
+
+	ops := []operations.Operation{
+		operations.NewCreate(&entities.Polyanet{Position: origin}),
+		operations.NewCreate(&entities.Soloon{Position: neighbour, Color: entities.RedSoloon}),
+		operations.NewDelete(&entities.Polyanet{Position: origin}),
+		operations.NewReplace(
+			&entities.Soloon{Position: neighbour, Color: entities.RedSoloon},
+			&entities.Soloon{Position: neighbour, Color: entities.BlueSoloon},
+		),
+	}
+
+	service := NewMegaverseService(repo, nil, nil)
+	report := newRunReport(context.Background(), "test")
+	require.NoError(t, service.applyOperations(context.Background(), report, ops))
+
+	require.Equal(t, []entities.Position{neighbour}, repo.created, "only the net change reaches the API")
+	require.Len(t, report.Operations, 1)
+	require.Equal(t, "create SOLOON(blue) at (0, 1)", report.Operations[0].String())
+	require.Equal(t, 1, report.Applied)
+}
+
+func TestReconcileControllerReconcilesWhenGoalFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "goal.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"goal": [["POLYANET", "SPACE"]]}`), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	trigger, err := goalfile.Watch(ctx, path)
+	require.NoError(t, err)
+
+	repo := newFakeRepository(2, 1)
+	desired := func(context.Context) (*entities.Megaverse, error) {
+		goal, err := goalfile.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		return goal.ToMegaverse()
+	}
+	objectAt := func(column int) entities.AstralObject {
+		current, err := repo.GetCurrentMap(context.Background())
+		require.NoError(t, err)
+		return current.Grid[0][column]
+	}
+
+	// An hour-long interval leaves the file watch as the only trigger after startup
+	controller := NewReconcileController(NewMegaverseService(repo, nil, nil), desired, time.Hour, trigger)
+	done := make(chan error, 1)
+	go func() { done <- controller.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return objectAt(0) != nil }, 5*time.Second, 10*time.Millisecond)
+	require.Nil(t, objectAt(1))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"goal": [["SPACE", "POLYANET"]]}`), 0o644))
+	require.Eventually(t, func() bool { return objectAt(0) == nil && objectAt(1) != nil }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,199 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/domain/operations"
+	"github.com/crossmint/megaverse-challenge/pkg/correlation"
+)
+
+// RunReport records the outcome of a single strategy execution or reconciliation pass.
+// Counters are safe to update from concurrent workers.
+type RunReport struct {
+	Name       string
+	RunID      string
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	Planned int
+	Applied int
+	Failed  int
+	Skipped int
+
+	// Operations lists what a reconciliation or operation run planned to apply; empty for strategy runs
+	Operations []operations.Operation
+
+	// Decisions records every interactive approval answer, in the order they were given
+	Decisions []Decision
+
+	// CircuitChanges lists the API client's circuit breaker state changes during the run
+	CircuitChanges []CircuitChange
+
+	mu sync.Mutex
+}
+
+// Decision captures the answer given for one proposed operation in interactive mode
+type Decision struct {
+	Operation operations.Operation
+	Answer    Answer
+	Prompted  bool // false when the answer came from an earlier "all" or "skip-type"
+	At        time.Time
+}
+
+// CircuitChange is one circuit breaker state change, such as "closed" to "open"
+type CircuitChange struct {
+	From, To string
+	At       time.Time
+}
+
+// RecordCircuitChange adds a circuit breaker state change observed while the run was in progress
+func (r *RunReport) RecordCircuitChange(change CircuitChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.CircuitChanges = append(r.CircuitChanges, change)
+}
+
+func newRunReport(ctx context.Context, name string) *RunReport {
+	return &RunReport{Name: name, RunID: correlation.RunID(ctx), StartedAt: time.Now()}
+}
+
+func (r *RunReport) recordPlanned() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Planned++
+}
+
+func (r *RunReport) recordApplied() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Applied++
+}
+
+func (r *RunReport) recordFailed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Failed++
+}
+
+func (r *RunReport) recordSkipped() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Skipped++
+}
+
+func (r *RunReport) recordDecision(op operations.Operation, answer Answer, prompted bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Decisions = append(r.Decisions, Decision{Operation: op, Answer: answer, Prompted: prompted, At: time.Now()})
+}
+
+func (r *RunReport) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FinishedAt = time.Now()
+}
+
+// Duration returns how long the run took
+func (r *RunReport) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// reportDocument is the JSON form of a RunReport. Objects are rendered as descriptions such as
+// "SOLOON(red)" so the document does not depend on the entity types.
+type reportDocument struct {
+	Name       string             `json:"name"`
+	RunID      string             `json:"run_id,omitempty"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+	DurationMS int64              `json:"duration_ms"`
+	Planned    int                `json:"planned"`
+	Applied    int                `json:"applied"`
+	Failed     int                `json:"failed"`
+	Skipped    int                `json:"skipped"`
+	Operations []string           `json:"operations,omitempty"`
+	Decisions  []decisionDocument `json:"decisions,omitempty"`
+	Circuit    []circuitDocument  `json:"circuit_breaker,omitempty"`
+}
+
+type circuitDocument struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	At   time.Time `json:"at"`
+}
+
+type decisionDocument struct {
+	Operation string    `json:"operation"`
+	Answer    string    `json:"answer"`
+	Prompted  bool      `json:"prompted"`
+	At        time.Time `json:"at"`
+}
+
+// MarshalJSON encodes the report for run artifacts such as report.json
+func (r *RunReport) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	doc := reportDocument{
+		Name:       r.Name,
+		RunID:      r.RunID,
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+		DurationMS: r.FinishedAt.Sub(r.StartedAt).Milliseconds(),
+		Planned:    r.Planned,
+		Applied:    r.Applied,
+		Failed:     r.Failed,
+		Skipped:    r.Skipped,
+	}
+	for _, op := range r.Operations {
+		doc.Operations = append(doc.Operations, op.String())
+	}
+	for _, d := range r.Decisions {
+		doc.Decisions = append(doc.Decisions, decisionDocument{
+			Operation: d.Operation.String(),
+			Answer:    d.Answer.String(),
+			Prompted:  d.Prompted,
+			At:        d.At,
+		})
+	}
+
+	for _, c := range r.CircuitChanges {
+		doc.Circuit = append(doc.Circuit, circuitDocument{From: c.From, To: c.To, At: c.At})
+	}
+
+	return json.Marshal(doc)
+}
+
+// runState carries per-run bookkeeping through the executors
+type runState struct {
+	report *RunReport
+
+	// replacements holds the current object for cells an approver agreed to replace,
+	// so dispatch deletes it before creating the planned object
+	mu           sync.Mutex
+	replacements map[entities.Position]entities.AstralObject
+}
+
+func newRunState(ctx context.Context, name string) *runState {
+	return &runState{report: newRunReport(ctx, name)}
+}
+
+func (r *runState) markReplacement(current entities.AstralObject) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.replacements == nil {
+		r.replacements = make(map[entities.Position]entities.AstralObject)
+	}
+	r.replacements[current.GetPosition()] = current
+}
+
+func (r *runState) takeReplacement(pos entities.Position) entities.AstralObject {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current := r.replacements[pos]
+	delete(r.replacements, pos)
+	return current
+}
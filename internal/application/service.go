@@ -3,20 +3,47 @@ package application
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/crossmint/megaverse-challenge/internal/application/strategies"
 	"github.com/crossmint/megaverse-challenge/internal/domain"
 	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/domain/operations"
+	"github.com/crossmint/megaverse-challenge/pkg/tracing"
 )
 
 // MegaverseService orchestrates the creation and management of megaverses
 type MegaverseService struct {
 	repository domain.MegaverseRepository
-	logger     *log.Logger
+	logger     *slog.Logger
 	limiter    rateLimiter
+	options    ExecutionOptions
+	approver   Approver
+	metrics    serviceMetrics
+	tracer     *tracing.Tracer
+	journal    domain.Journal
+}
+
+// ExecutionOptions tunes how plans are dispatched. Zero values fall back to the plan's hints
+// and then to built-in defaults.
+type ExecutionOptions struct {
+	// Order overrides the execution order requested by the strategy when set
+	Order *strategies.ExecutionOrder
+
+	// MaxWorkers bounds the parallel worker pool
+	MaxWorkers int
+
+	// BatchSize is used for OrderBatched when the plan does not specify one
+	BatchSize int
+
+	// BatchCooldown pauses between consecutive batches so bursts stay under the API's rate limits
+	BatchCooldown time.Duration
+
+	// VerifyBatches fetches the current map after each batch and reports objects that did not land
+	VerifyBatches bool
 }
 
 type rateLimiter interface {
@@ -24,89 +51,224 @@ type rateLimiter interface {
 }
 
 // NewMegaverseService creates a new megaverse service
-func NewMegaverseService(repository domain.MegaverseRepository, logger *log.Logger, limiter rateLimiter) *MegaverseService {
+func NewMegaverseService(repository domain.MegaverseRepository, logger *slog.Logger, limiter rateLimiter) *MegaverseService {
 	if logger == nil {
-		logger = log.Default()
+		logger = slog.Default()
 	}
 	return &MegaverseService{
 		repository: repository,
 		logger:     logger,
 		limiter:    limiter,
+		metrics:    newServiceMetrics(nil),
 	}
 }
 
-// ExecuteStrategy executes a pattern strategy to create a megaverse
-func (s *MegaverseService) ExecuteStrategy(ctx context.Context, strategy strategies.PatternStrategy) error {
-	s.logger.Printf("Executing strategy: %s\n", strategy.GetName())
+// WithExecutionOptions configures how subsequent plans are dispatched
+func (s *MegaverseService) WithExecutionOptions(options ExecutionOptions) *MegaverseService {
+	s.options = options
+	return s
+}
+
+// WithApprover asks approver before every change is dispatched; nil disables interactive approval
+func (s *MegaverseService) WithApprover(approver Approver) *MegaverseService {
+	s.approver = approver
+	return s
+}
+
+// ExecuteStrategy executes a pattern strategy to create a megaverse.
+// The returned report is populated even when the run fails part-way.
+func (s *MegaverseService) ExecuteStrategy(ctx context.Context, strategy strategies.PatternStrategy) (report *RunReport, err error) {
+	s.logger.InfoContext(ctx, "executing strategy", "strategy", strategy.GetName())
+
+	run := newRunState(ctx, strategy.GetName())
+	report = run.report
+
+	ctx, span := s.tracer.Start(ctx, "strategy.run", tracing.KindInternal, tracing.String("strategy", strategy.GetName()))
+	defer func() {
+		report.finish()
+		s.recordRunFinished(ctx, report, err)
+		finishRunSpan(span, report, err)
+	}()
 
 	// Ask the strategy for a creation plan (objects plus execution hints such as order/batch size)
 	plan, err := strategy.GeneratePlan(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to generate plan: %w", err)
+		return run.report, fmt.Errorf("failed to generate plan: %w", err)
 	}
 
-	objects := plan.Objects
+	// Grid size is only reliable once the plan exists; goal-driven strategies learn it while planning.
+	width, height := strategy.GetGridSize()
+	s.recordRunStarted(ctx, report, width, height)
 
-	s.logger.Printf("Generated plan with %d objects\n", len(objects))
+	s.recordPlanGenerated(ctx, plan.Size())
+	if total := plan.Size(); total >= 0 {
+		s.logger.InfoContext(ctx, "generated plan", "objects", total)
+	} else {
+		s.logger.InfoContext(ctx, "generated streamed plan")
+	}
 
 	execOrder := plan.Order
-	if execOrder == 0 {
-		execOrder = strategies.OrderSequential
+	if s.options.Order != nil {
+		execOrder = *s.options.Order
 	}
 
 	batchSize := plan.BatchSize
+	if batchSize <= 0 {
+		batchSize = s.options.BatchSize
+	}
 	if batchSize <= 0 {
 		batchSize = 5
 	}
 
+	span.SetAttributes(tracing.String("order", execOrder.String()))
+
+	plan, err = s.preparePlan(ctx, run, plan)
+	if err != nil {
+		return run.report, err
+	}
+
 	switch execOrder {
 	case strategies.OrderParallel:
-		return s.createObjectsParallel(ctx, objects)
+		err = s.createObjectsParallel(ctx, run, plan)
 	case strategies.OrderBatched:
-		return s.createObjectsBatched(ctx, objects, batchSize)
+		err = s.createObjectsBatched(ctx, run, plan, batchSize)
 	default:
-		return s.createObjectsSequential(ctx, objects)
+		err = s.createObjectsSequential(ctx, run, plan)
 	}
+	return run.report, err
 }
 
-// createObjectsSequential creates objects one by one
-func (s *MegaverseService) createObjectsSequential(ctx context.Context, objects []entities.AstralObject) error {
-	totalObjects := len(objects)
-	var errs []error
+// preparePlan wraps the plan so every object is counted in the report and, in interactive mode,
+// approved before it reaches the executors. It sits between planning and dispatch.
+func (s *MegaverseService) preparePlan(ctx context.Context, run *runState, plan strategies.CreationPlan) (strategies.CreationPlan, error) {
+	var gate *approvalGate
+	var current *entities.Megaverse
+
+	if s.approver != nil {
+		if err := s.waitForRateLimit(ctx); err != nil {
+			return plan, err
+		}
+		var err error
+		current, err = s.repository.GetCurrentMap(ctx)
+		if err != nil {
+			return plan, fmt.Errorf("failed to fetch current map for approval: %w", err)
+		}
+		gate = newApprovalGate(s.approver, run.report)
+	}
+
+	var gateErr error
+
+	prepared := plan
+	prepared.Objects = nil
+	prepared.SizeHint = max(plan.Size(), 0)
+	prepared.Source = func(ctx context.Context, yield func(entities.AstralObject) bool) error {
+		err := plan.Each(ctx, func(obj entities.AstralObject) bool {
+			run.report.recordPlanned()
+			if gate == nil {
+				return yield(obj)
+			}
+
+			have := cellAt(current, obj.GetPosition().Row, obj.GetPosition().Column)
+			if entities.SameObject(have, obj) {
+				run.report.recordSkipped()
+				s.recordOperation(ctx, domain.JournalObjectSkipped, operations.NewCreate(obj), nil)
+				s.metrics.observeObject(obj.GetType(), outcomeSkipped)
+				return true
+			}
 
-	for i, obj := range objects {
+			op, _ := operations.Between(obj.GetPosition(), have, obj)
+			apply, stop, err := gate.allow(ctx, op)
+			if err != nil {
+				gateErr = err
+				return false
+			}
+			if !apply {
+				run.report.recordSkipped()
+				s.recordOperation(ctx, domain.JournalObjectSkipped, op, nil)
+				s.metrics.observeObject(obj.GetType(), outcomeSkipped)
+				return !stop
+			}
+			if op.Kind == operations.Replace {
+				run.markReplacement(have)
+			}
+			return yield(obj)
+		})
+		if gateErr != nil {
+			return gateErr
+		}
+		return err
+	}
+
+	return prepared, nil
+}
+
+// createObjectsSequential creates objects one by one
+func (s *MegaverseService) createObjectsSequential(ctx context.Context, run *runState, plan strategies.CreationPlan) error {
+	totalObjects := plan.Size()
+	errCount := 0
+	i := 0
+	var stopErr error
+
+	iterErr := plan.Each(ctx, func(obj entities.AstralObject) bool {
+		i++
 		if err := ctx.Err(); err != nil {
-			return fmt.Errorf("context cancelled: %w", err)
+			stopErr = fmt.Errorf("context cancelled: %w", err)
+			return false
 		}
 
-		s.logger.Printf("[%d/%d] Creating %s at position (%d, %d)\n",
-			i+1, totalObjects, obj.GetType(), obj.GetPosition().Row, obj.GetPosition().Column)
+		opCtx := withOperation(ctx)
+		s.logger.InfoContext(opCtx, "creating object", append(objectFields(obj), progressFields(i, totalObjects)...)...)
 
-		if err := s.waitForRateLimit(ctx); err != nil {
-			return err
+		if err := s.waitForRateLimit(opCtx); err != nil {
+			stopErr = err
+			return false
 		}
 
-		if err := s.createObject(ctx, obj); err != nil {
-			s.logger.Printf("Failed to create object at (%d, %d): %v\n",
-				obj.GetPosition().Row, obj.GetPosition().Column, err)
-			errs = append(errs, err)
+		if err := s.dispatch(opCtx, run, obj); err != nil {
+			s.logger.ErrorContext(opCtx, "failed to create object", append(objectFields(obj), "error", err)...)
+			errCount++
 		}
+		return true
+	})
+	if stopErr != nil {
+		return stopErr
+	}
+	if iterErr != nil {
+		return fmt.Errorf("failed to stream plan: %w", iterErr)
 	}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("encountered %d errors during sequential creation", len(errs))
+	if errCount > 0 {
+		return fmt.Errorf("encountered %d errors during sequential creation", errCount)
 	}
 
 	return nil
 }
 
-// createObjectsParallel uses a fixed-size worker pool so we can overlap work while keeping the API traffic predictable
-func (s *MegaverseService) createObjectsParallel(ctx context.Context, objects []entities.AstralObject) error {
-	const maxWorkers = 5 // tuned to respect Crossmint rate limits without incurring long queues
+// createObjectsParallel uses a fixed-size worker pool so we can overlap work while keeping the API traffic predictable.
+// Channels are bounded by the pool size rather than the plan size, so streamed plans run in constant memory.
+func (s *MegaverseService) createObjectsParallel(ctx context.Context, run *runState, plan strategies.CreationPlan) error {
+	maxWorkers := s.options.MaxWorkers
+	if maxWorkers <= 0 {
+		maxWorkers = 5 // tuned to respect Crossmint rate limits without incurring long queues
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	var wg sync.WaitGroup
-	objectChan := make(chan entities.AstralObject, len(objects))
-	errorChan := make(chan error, len(objects))
+	var errCount atomic.Int64
+	var fatalErr error
+	var fatalOnce sync.Once
+	objectChan := make(chan entities.AstralObject, maxWorkers)
+
+	// A worker that cannot continue cancels the run so the feeder stops instead of blocking on a full channel.
+	abort := func(err error) {
+		errCount.Add(1)
+		fatalOnce.Do(func() {
+			fatalErr = err
+			cancel()
+		})
+	}
 
 	for i := 0; i < maxWorkers; i++ {
 		wg.Add(1)
@@ -114,96 +276,275 @@ func (s *MegaverseService) createObjectsParallel(ctx context.Context, objects []
 			defer wg.Done()
 			for obj := range objectChan {
 				if err := ctx.Err(); err != nil {
-					errorChan <- err
+					abort(err)
 					return
 				}
 
 				if err := s.waitForRateLimit(ctx); err != nil {
-					s.logger.Printf("[Worker %d] rate limit wait failed: %v\n", workerID, err)
-					errorChan <- err
+					s.logger.ErrorContext(ctx, "rate limit wait failed", "worker", workerID, "error", err)
+					abort(err)
 					return
 				}
 
-				s.logger.Printf("[Worker %d] Creating %s at position (%d, %d)\n",
-					workerID, obj.GetType(), obj.GetPosition().Row, obj.GetPosition().Column)
+				opCtx := withOperation(ctx)
+				s.logger.InfoContext(opCtx, "creating object", append(objectFields(obj), "worker", workerID)...)
 
-				if err := s.createObject(ctx, obj); err != nil {
-					s.logger.Printf("[Worker %d] Failed to create object: %v\n", workerID, err)
-					errorChan <- err
+				if err := s.dispatch(opCtx, run, obj); err != nil {
+					s.logger.ErrorContext(opCtx, "failed to create object", append(objectFields(obj), "worker", workerID, "error", err)...)
+					errCount.Add(1)
 				}
 			}
 		}(i)
 	}
 
-	for _, obj := range objects {
-		objectChan <- obj
-	}
+	iterErr := plan.Each(ctx, func(obj entities.AstralObject) bool {
+		select {
+		case objectChan <- obj:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
 	close(objectChan)
 
 	wg.Wait()
-	close(errorChan)
 
-	var errs []error
-	for err := range errorChan {
-		errs = append(errs, err)
+	if fatalErr == nil {
+		// The caller's context may end while every worker is between objects, which no worker reports.
+		fatalErr = ctx.Err()
+	}
+	if fatalErr != nil {
+		return fmt.Errorf("parallel creation aborted: %w", fatalErr)
+	}
+
+	if iterErr != nil {
+		return fmt.Errorf("failed to stream plan: %w", iterErr)
 	}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("encountered %d errors during parallel creation", len(errs))
+	if n := errCount.Load(); n > 0 {
+		return fmt.Errorf("encountered %d errors during parallel creation", n)
 	}
 
 	return nil
 }
 
-// createObjectsBatched creates objects in batches
-func (s *MegaverseService) createObjectsBatched(ctx context.Context, objects []entities.AstralObject, batchSize int) error {
-	totalObjects := len(objects)
+// createObjectsBatched creates each batch concurrently and waits for the whole batch to finish before
+// starting the next one, optionally cooling down and verifying the batch against the live map in between.
+func (s *MegaverseService) createObjectsBatched(ctx context.Context, run *runState, plan strategies.CreationPlan, batchSize int) error {
+	totalObjects := plan.Size()
+	errCount := 0
+	processed := 0
+	batch := make([]entities.AstralObject, 0, batchSize)
+
+	runBatch := func() error {
+		if processed > 0 && s.options.BatchCooldown > 0 {
+			if err := sleepContext(ctx, s.options.BatchCooldown); err != nil {
+				return fmt.Errorf("context cancelled: %w", err)
+			}
+		}
 
-	var errs []error
+		s.logger.InfoContext(ctx, "processing batch", append([]any{"first", processed + 1, "last", processed + len(batch)}, totalField(totalObjects)...)...)
 
-	for i := 0; i < totalObjects; i += batchSize {
-		end := i + batchSize
-		if end > totalObjects {
-			end = totalObjects
+		applied, failed, err := s.runBatch(ctx, run, batch)
+		errCount += failed
+		if err != nil {
+			return err
 		}
 
-		batch := objects[i:end]
-		s.logger.Printf("Processing batch %d-%d of %d\n", i+1, end, totalObjects)
-
-		// Create batch sequentially (could be parallel within batch)
-		for _, obj := range batch {
-			if err := ctx.Err(); err != nil {
-				return fmt.Errorf("context cancelled: %w", err)
+		// Failed creates are already counted; verifying them again would count them twice.
+		if s.options.VerifyBatches && len(applied) > 0 {
+			missing, err := s.verifyBatch(ctx, applied)
+			if err != nil {
+				return err
 			}
+			errCount += missing
+		}
+
+		processed += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	var stopErr error
+	iterErr := plan.Each(ctx, func(obj entities.AstralObject) bool {
+		batch = append(batch, obj)
+		if len(batch) < batchSize {
+			return true
+		}
+		stopErr = runBatch()
+		return stopErr == nil
+	})
+	if stopErr == nil && iterErr == nil && len(batch) > 0 {
+		stopErr = runBatch()
+	}
+	if stopErr != nil {
+		return stopErr
+	}
+	if iterErr != nil {
+		return fmt.Errorf("failed to stream plan: %w", iterErr)
+	}
+
+	if errCount > 0 {
+		return fmt.Errorf("encountered %d errors during batched creation", errCount)
+	}
+
+	return nil
+}
+
+// runBatch creates every object of a batch concurrently and returns once all of them have finished.
+// It returns the objects the API accepted and how many creations failed, and an error only when the
+// run itself must stop.
+func (s *MegaverseService) runBatch(ctx context.Context, run *runState, batch []entities.AstralObject) ([]entities.AstralObject, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, fmt.Errorf("context cancelled: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var applied []entities.AstralObject
+	failed := 0
+	var stopErr error
+
+	for _, obj := range batch {
+		wg.Add(1)
+		go func(obj entities.AstralObject) {
+			defer wg.Done()
+			ctx := withOperation(ctx)
 
 			if err := s.waitForRateLimit(ctx); err != nil {
-				return err
+				mu.Lock()
+				if stopErr == nil {
+					stopErr = err
+				}
+				mu.Unlock()
+				return
 			}
 
-			if err := s.createObject(ctx, obj); err != nil {
-				s.logger.Printf("Failed to create object: %v\n", err)
-				errs = append(errs, err)
+			if err := s.dispatch(ctx, run, obj); err != nil {
+				s.logger.ErrorContext(ctx, "failed to create object", append(objectFields(obj), "error", err)...)
+				mu.Lock()
+				failed++
+				mu.Unlock()
+				return
 			}
+			mu.Lock()
+			applied = append(applied, obj)
+			mu.Unlock()
+		}(obj)
+	}
+
+	wg.Wait()
+	return applied, failed, stopErr
+}
+
+// verifyBatch fetches the live map and counts applied objects that are not present as planned
+func (s *MegaverseService) verifyBatch(ctx context.Context, applied []entities.AstralObject) (int, error) {
+	if err := s.waitForRateLimit(ctx); err != nil {
+		return 0, err
+	}
+
+	current, err := s.repository.GetCurrentMap(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify batch: %w", err)
+	}
+
+	missing := 0
+	for _, obj := range applied {
+		pos := obj.GetPosition()
+		actual, err := current.GetObject(pos.Row, pos.Column)
+		if err != nil || !entities.SameObject(obj, actual) {
+			s.logger.WarnContext(ctx, "verification failed: object missing", objectFields(obj)...)
+			missing++
 		}
 	}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("encountered %d errors during batched creation", len(errs))
+	return missing, nil
+}
+
+// sleepContext waits for d or until ctx is cancelled, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
+}
 
-	return nil
+// objectFields returns the structured log fields identifying obj
+func objectFields(obj entities.AstralObject) []any {
+	pos := obj.GetPosition()
+	return []any{"type", obj.GetType(), "row", pos.Row, "column", pos.Column}
+}
+
+// progressFields returns the index of an object within its plan, omitting the total for streamed plans of unknown size
+func progressFields(i, total int) []any {
+	return append([]any{"index", i}, totalField(total)...)
+}
+
+func totalField(total int) []any {
+	if total < 0 {
+		return nil
+	}
+	return []any{"total", total}
 }
 
 func (s *MegaverseService) waitForRateLimit(ctx context.Context) error {
 	if s.limiter == nil {
 		return nil
 	}
+	start := time.Now()
 	if err := s.limiter.Wait(ctx); err != nil {
 		return fmt.Errorf("rate limit wait failed: %w", err)
 	}
+	s.metrics.observeLimiterWait(time.Since(start))
 	return nil
 }
 
+// dispatch creates a planned object, first deleting the current occupant when an approver agreed to
+// replace it, and records the outcome in the run report and journal
+func (s *MegaverseService) dispatch(ctx context.Context, run *runState, obj entities.AstralObject) error {
+	s.metrics.inFlight.With().Inc()
+	defer s.metrics.inFlight.With().Dec()
+
+	ctx, span := s.tracer.Start(ctx, "object.create", tracing.KindInternal, objectAttributes(obj)...)
+	defer span.Finish()
+
+	op := operations.NewCreate(obj)
+	if current := run.takeReplacement(obj.GetPosition()); current != nil {
+		op = operations.NewReplace(current, obj)
+	}
+	s.recordOperation(ctx, domain.JournalObjectStarted, op, nil)
+
+	err := s.replaceAndCreate(ctx, op)
+	span.RecordError(err)
+	if err != nil {
+		run.report.recordFailed()
+		s.recordOperation(ctx, domain.JournalObjectFailed, op, err)
+		s.metrics.observeObject(obj.GetType(), outcomeFailed)
+		return err
+	}
+	run.report.recordApplied()
+	s.recordOperation(ctx, domain.JournalObjectApplied, op, nil)
+	s.metrics.observeObject(obj.GetType(), outcomeCreated)
+	return nil
+}
+
+func (s *MegaverseService) replaceAndCreate(ctx context.Context, op operations.Operation) error {
+	if current := op.Previous; current != nil {
+		if err := s.repository.DeleteObject(ctx, current.GetType(), current.GetPosition()); err != nil {
+			return fmt.Errorf("failed to delete %s before replacing it: %w", current.GetType(), err)
+		}
+		if err := s.waitForRateLimit(ctx); err != nil {
+			return err
+		}
+	}
+	return s.createObject(ctx, op.Object)
+}
+
 // createObject creates a single astral object using the repository
 func (s *MegaverseService) createObject(ctx context.Context, obj entities.AstralObject) error {
 	if err := obj.Validate(); err != nil {
@@ -229,7 +570,7 @@ func (s *MegaverseService) createObject(ctx context.Context, obj entities.Astral
 
 // ClearMegaverse removes all objects from the megaverse
 func (s *MegaverseService) ClearMegaverse(ctx context.Context, width, height int) error {
-	s.logger.Printf("Clearing megaverse (%dx%d)\n", width, height)
+	s.logger.InfoContext(ctx, "clearing megaverse", "width", width, "height", height)
 
 	successCount := 0
 	errorCount := 0
@@ -246,7 +587,7 @@ func (s *MegaverseService) ClearMegaverse(ctx context.Context, width, height int
 				err := s.repository.DeleteObject(ctx, objType, pos)
 				if err == nil {
 					successCount++
-					s.logger.Printf("Deleted %s at (%d, %d)\n", objType, row, col)
+					s.logger.InfoContext(ctx, "deleted object", "type", objType, "row", row, "column", col)
 					deleted = true
 					break // Successfully deleted something, move to next position
 				}
@@ -262,8 +603,7 @@ func (s *MegaverseService) ClearMegaverse(ctx context.Context, width, height int
 		}
 	}
 
-	s.logger.Printf("Clear complete: %d objects removed, %d positions checked, %d positions unchanged\n",
-		successCount, width*height, errorCount)
+	s.logger.InfoContext(ctx, "clear complete", "removed", successCount, "checked", width*height, "unchanged", errorCount)
 
 	return nil
 }
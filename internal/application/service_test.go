@@ -0,0 +1,165 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/application/strategies"
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+)
+
+// fakeRepository records creations against an in-memory megaverse
+type fakeRepository struct {
+	mu       sync.Mutex
+	world    *entities.Megaverse
+	inFlight int
+	peak     int
+	created  []entities.Position
+	gets     int
+	drop     map[entities.Position]bool
+	fail     map[entities.Position]bool
+	delay    time.Duration
+}
+
+func newFakeRepository(width, height int) *fakeRepository {
+	return &fakeRepository{world: entities.NewMegaverse(width, height), drop: map[entities.Position]bool{}, fail: map[entities.Position]bool{}}
+}
+
+func (f *fakeRepository) place(ctx context.Context, obj entities.AstralObject) error {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.peak {
+		f.peak = f.inFlight
+	}
+	f.mu.Unlock()
+
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.inFlight--
+	if f.fail[obj.GetPosition()] {
+		return errors.New("create rejected")
+	}
+	f.created = append(f.created, obj.GetPosition())
+	if f.drop[obj.GetPosition()] {
+		return nil
+	}
+	return f.world.PlaceObject(obj)
+}
+
+func (f *fakeRepository) CreatePolyanet(ctx context.Context, pos entities.Position) error {
+	return f.place(ctx, &entities.Polyanet{Position: pos})
+}
+func (f *fakeRepository) CreateSoloon(ctx context.Context, pos entities.Position, color entities.SoloonColor) error {
+	return f.place(ctx, &entities.Soloon{Position: pos, Color: color})
+}
+func (f *fakeRepository) CreateCometh(ctx context.Context, pos entities.Position, direction entities.ComethDirection) error {
+	return f.place(ctx, &entities.Cometh{Position: pos, Direction: direction})
+}
+func (f *fakeRepository) DeleteObject(_ context.Context, _ string, pos entities.Position) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.world.Grid[pos.Row][pos.Column] = nil
+	return nil
+}
+func (f *fakeRepository) GetGoalMap(context.Context) (*domain.GoalMap, error) { return nil, nil }
+func (f *fakeRepository) GetCurrentMap(context.Context) (*entities.Megaverse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gets++
+	snapshot := entities.NewMegaverse(f.world.Width, f.world.Height)
+	for _, row := range f.world.Grid {
+		for _, obj := range row {
+			if obj != nil {
+				_ = snapshot.PlaceObject(obj)
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+func rowStrategy(width int) strategies.PatternStrategy {
+	return strategies.NewGeneratedPatternStrategy("row", width, 1, func(row, col int) entities.AstralObject {
+		return &entities.Polyanet{Position: entities.Position{Row: row, Column: col}}
+	})
+}
+
+func TestParallelExecutionReportsCancellation(t *testing.T) {
+	repo := newFakeRepository(20, 1)
+	repo.delay = 20 * time.Millisecond
+
+	service := NewMegaverseService(repo, nil, nil).WithExecutionOptions(ExecutionOptions{MaxWorkers: 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := service.ExecuteStrategy(ctx, rowStrategy(20))
+	require.ErrorIs(t, err, context.Canceled)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	require.Less(t, len(repo.created), 20, "the run stops once the context is cancelled")
+}
+
+func TestBatchedExecutionRunsBatchesConcurrentlyWithBarrier(t *testing.T) {
+	repo := newFakeRepository(7, 1)
+	repo.delay = 20 * time.Millisecond
+
+	order := strategies.OrderBatched
+	service := NewMegaverseService(repo, nil, nil).WithExecutionOptions(ExecutionOptions{
+		Order:         &order,
+		BatchSize:     3,
+		VerifyBatches: true,
+	})
+
+	_, err := service.ExecuteStrategy(context.Background(), rowStrategy(7))
+	require.NoError(t, err)
+
+	require.Len(t, repo.created, 7)
+	require.Equal(t, 3, repo.peak, "objects within a batch should be created concurrently")
+	require.Equal(t, 3, repo.gets, "each batch should be verified once")
+
+	// The barrier means every object of a batch lands before any object of the next batch.
+	for i, pos := range repo.created {
+		require.Equal(t, i/3, pos.Column/3, "object %+v escaped its batch", pos)
+	}
+}
+
+func TestBatchedExecutionReportsUnverifiedObjects(t *testing.T) {
+	repo := newFakeRepository(4, 1)
+	repo.drop[entities.Position{Row: 0, Column: 2}] = true
+
+	order := strategies.OrderBatched
+	service := NewMegaverseService(repo, nil, nil).WithExecutionOptions(ExecutionOptions{
+		Order:         &order,
+		BatchSize:     2,
+		VerifyBatches: true,
+	})
+
+	_, err := service.ExecuteStrategy(context.Background(), rowStrategy(4))
+	require.ErrorContains(t, err, "encountered 1 errors during batched creation")
+}
+
+func TestBatchedExecutionCountsFailedCreatesOnce(t *testing.T) {
+	repo := newFakeRepository(4, 1)
+	repo.fail[entities.Position{Row: 0, Column: 1}] = true
+
+	order := strategies.OrderBatched
+	service := NewMegaverseService(repo, nil, nil).WithExecutionOptions(ExecutionOptions{
+		Order:         &order,
+		BatchSize:     2,
+		VerifyBatches: true,
+	})
+
+	_, err := service.ExecuteStrategy(context.Background(), rowStrategy(4))
+	require.ErrorContains(t, err, "encountered 1 errors during batched creation")
+}
@@ -0,0 +1,76 @@
+package strategies
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+)
+
+// CellFunc decides which object, if any, belongs at a grid cell. Returning nil leaves the cell empty.
+type CellFunc func(row, col int) entities.AstralObject
+
+// GeneratedPatternStrategy streams a pattern computed cell by cell, so arbitrarily large grids
+// never have to be held in memory at once.
+type GeneratedPatternStrategy struct {
+	name   string
+	width  int
+	height int
+	cell   CellFunc
+	order  ExecutionOrder
+}
+
+// NewGeneratedPatternStrategy creates a strategy that evaluates cell for every position of a width x height grid
+func NewGeneratedPatternStrategy(name string, width, height int, cell CellFunc) *GeneratedPatternStrategy {
+	return &GeneratedPatternStrategy{
+		name:   name,
+		width:  width,
+		height: height,
+		cell:   cell,
+		order:  OrderParallel,
+	}
+}
+
+// GetName returns the name of the strategy
+func (s *GeneratedPatternStrategy) GetName() string {
+	return s.name
+}
+
+// GeneratePlan returns a streamed plan; cells are only evaluated while the plan is being executed
+func (s *GeneratedPatternStrategy) GeneratePlan(_ context.Context) (CreationPlan, error) {
+	if s.width <= 0 || s.height <= 0 {
+		return CreationPlan{}, fmt.Errorf("invalid grid size: %dx%d", s.width, s.height)
+	}
+	if s.cell == nil {
+		return CreationPlan{}, fmt.Errorf("generated pattern %q has no cell function", s.name)
+	}
+
+	return CreationPlan{
+		Source: s.stream,
+		Order:  s.order,
+	}, nil
+}
+
+func (s *GeneratedPatternStrategy) stream(ctx context.Context, yield func(entities.AstralObject) bool) error {
+	for row := 0; row < s.height; row++ {
+		// Checking once per row keeps cancellation responsive without paying for it on every cell.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for col := 0; col < s.width; col++ {
+			obj := s.cell(row, col)
+			if obj == nil {
+				continue
+			}
+			if !yield(obj) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// GetGridSize returns the dimensions of the megaverse for this pattern
+func (s *GeneratedPatternStrategy) GetGridSize() (width, height int) {
+	return s.width, s.height
+}
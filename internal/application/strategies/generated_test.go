@@ -0,0 +1,64 @@
+package strategies
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+)
+
+func checkerboard(row, col int) entities.AstralObject {
+	if (row+col)%2 != 0 {
+		return nil
+	}
+	return &entities.Polyanet{Position: entities.Position{Row: row, Column: col}}
+}
+
+func TestGeneratedPatternStreamsLargeGrid(t *testing.T) {
+	strategy := NewGeneratedPatternStrategy("checkerboard", 1000, 500, checkerboard)
+
+	plan, err := strategy.GeneratePlan(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, plan.Objects)
+	require.Equal(t, -1, plan.Size())
+
+	count := 0
+	err = plan.Each(context.Background(), func(obj entities.AstralObject) bool {
+		pos := obj.GetPosition()
+		require.Zero(t, (pos.Row+pos.Column)%2)
+		count++
+		return true
+	})
+	require.NoError(t, err)
+	require.Equal(t, 250_000, count)
+}
+
+func TestGeneratedPatternStopsWhenYieldReturnsFalse(t *testing.T) {
+	strategy := NewGeneratedPatternStrategy("checkerboard", 10, 10, checkerboard)
+
+	plan, err := strategy.GeneratePlan(context.Background())
+	require.NoError(t, err)
+
+	count := 0
+	err = plan.Each(context.Background(), func(entities.AstralObject) bool {
+		count++
+		return count < 3
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+}
+
+func TestGeneratedPatternHonoursCancellation(t *testing.T) {
+	strategy := NewGeneratedPatternStrategy("checkerboard", 10, 10, checkerboard)
+
+	plan, err := strategy.GeneratePlan(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = plan.Each(ctx, func(entities.AstralObject) bool { return true })
+	require.ErrorIs(t, err, context.Canceled)
+}
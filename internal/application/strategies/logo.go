@@ -3,6 +3,7 @@ package strategies
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 
 	"github.com/crossmint/megaverse-challenge/internal/domain"
@@ -12,13 +13,18 @@ import (
 // LogoPatternStrategy implements the pattern based on the goal map for Phase 2
 type LogoPatternStrategy struct {
 	repository domain.MegaverseRepository
+	logger     *slog.Logger
 	goalMap    *domain.GoalMap
 }
 
 // NewLogoPatternStrategy creates a new logo pattern strategy
-func NewLogoPatternStrategy(repository domain.MegaverseRepository) *LogoPatternStrategy {
+func NewLogoPatternStrategy(repository domain.MegaverseRepository, logger *slog.Logger) *LogoPatternStrategy {
+	if logger == nil {
+		logger = slog.Default()
+	}
 	return &LogoPatternStrategy{
 		repository: repository,
+		logger:     logger,
 	}
 }
 
@@ -118,7 +124,7 @@ func (s *LogoPatternStrategy) parseGoalCell(cellValue string, row, col int) enti
 
 	default:
 		// The API occasionally introduces new tokens; log them so we can extend support without failing the build.
-		fmt.Printf("Warning: Unknown cell value '%s' at position (%d, %d)\n", cellValue, row, col)
+		s.logger.Warn("unknown goal cell value", "value", cellValue, "row", row, "column", col)
 		return nil
 	}
 }
@@ -38,7 +38,7 @@ func TestLogoPatternGeneratePlan(t *testing.T) {
 		{"SPACE", "RED_SOLOON", "LEFT_COMETH"},
 	}}}
 
-	strategy := NewLogoPatternStrategy(repo)
+	strategy := NewLogoPatternStrategy(repo, nil)
 	plan, err := strategy.GeneratePlan(context.Background())
 	require.NoError(t, err)
 
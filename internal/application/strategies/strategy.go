@@ -2,6 +2,8 @@ package strategies
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
 )
@@ -32,9 +34,73 @@ const (
 	OrderBatched
 )
 
+// String returns the configuration name of the execution order
+func (o ExecutionOrder) String() string {
+	switch o {
+	case OrderSequential:
+		return "sequential"
+	case OrderParallel:
+		return "parallel"
+	case OrderBatched:
+		return "batched"
+	default:
+		return fmt.Sprintf("ExecutionOrder(%d)", int(o))
+	}
+}
+
+// ParseExecutionOrder converts a configuration value such as "batched" into an ExecutionOrder
+func ParseExecutionOrder(value string) (ExecutionOrder, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "sequential":
+		return OrderSequential, nil
+	case "parallel":
+		return OrderParallel, nil
+	case "batched":
+		return OrderBatched, nil
+	default:
+		return 0, fmt.Errorf("unknown execution order %q: must be sequential, parallel, or batched", value)
+	}
+}
+
+// ObjectSource lazily yields the objects of a plan in creation order.
+// Iteration stops early when yield returns false.
+type ObjectSource func(ctx context.Context, yield func(entities.AstralObject) bool) error
+
 // CreationPlan represents a plan for creating objects in the megaverse
 type CreationPlan struct {
-	Objects   []entities.AstralObject
+	Objects []entities.AstralObject
+	// Source streams objects instead of materialising them in Objects, so very large
+	// patterns run in constant memory. When set, Objects is ignored.
+	Source    ObjectSource
+	SizeHint  int // Optional object count for streamed plans, used for progress reporting
 	Order     ExecutionOrder
 	BatchSize int // Used only for OrderBatched
 }
+
+// Each calls yield for every object in the plan, preferring the lazy Source when present.
+func (p CreationPlan) Each(ctx context.Context, yield func(entities.AstralObject) bool) error {
+	if p.Source != nil {
+		return p.Source(ctx, yield)
+	}
+
+	for _, obj := range p.Objects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !yield(obj) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Size returns the number of objects in the plan, or -1 when a streamed plan has no size hint.
+func (p CreationPlan) Size() int {
+	if p.Source == nil {
+		return len(p.Objects)
+	}
+	if p.SizeHint > 0 {
+		return p.SizeHint
+	}
+	return -1
+}
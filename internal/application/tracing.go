@@ -0,0 +1,38 @@
+package application
+
+import (
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/domain/operations"
+	"github.com/crossmint/megaverse-challenge/pkg/tracing"
+)
+
+// WithTracer records a span per run and per object; nil disables tracing
+func (s *MegaverseService) WithTracer(tracer *tracing.Tracer) *MegaverseService {
+	s.tracer = tracer
+	return s
+}
+
+func objectAttributes(obj entities.AstralObject) []tracing.Attribute {
+	pos := obj.GetPosition()
+	return []tracing.Attribute{
+		tracing.String("object.type", obj.GetType()),
+		tracing.Int("position.row", pos.Row),
+		tracing.Int("position.column", pos.Column),
+	}
+}
+
+func operationAttributes(op operations.Operation) []tracing.Attribute {
+	return append(objectAttributes(op.Subject()), tracing.String("operation", op.Kind.String()))
+}
+
+// finishRunSpan annotates a run span with the report's counters and ends it
+func finishRunSpan(span *tracing.Span, report *RunReport, err error) {
+	span.SetAttributes(
+		tracing.Int("objects.planned", report.Planned),
+		tracing.Int("objects.applied", report.Applied),
+		tracing.Int("objects.failed", report.Failed),
+		tracing.Int("objects.skipped", report.Skipped),
+	)
+	span.RecordError(err)
+	span.Finish()
+}
@@ -6,3 +6,25 @@ type AstralObject interface {
 	GetType() string
 	Validate() error
 }
+
+// SameObject reports whether a and b describe the same object, including colour and direction.
+// Two nil objects (empty cells) are considered the same.
+func SameObject(a, b AstralObject) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if a.GetType() != b.GetType() || a.GetPosition() != b.GetPosition() {
+		return false
+	}
+
+	switch x := a.(type) {
+	case *Soloon:
+		y, ok := b.(*Soloon)
+		return ok && x.Color == y.Color
+	case *Cometh:
+		y, ok := b.(*Cometh)
+		return ok && x.Direction == y.Direction
+	default:
+		return true
+	}
+}
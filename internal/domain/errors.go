@@ -2,6 +2,8 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 
 	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
 )
@@ -25,15 +27,86 @@ func init() {
 	entities.RegisterMegaverseErrors(ErrOutOfBounds)
 }
 
-// APIError represents a detailed API error with status code
+// API error kinds. An *APIError unwraps to the kind matching its status code, so callers can test
+// for them with errors.Is.
+var (
+	ErrRateLimited = errors.New("rate limited by the API")
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflicting state")
+	ErrValidation  = errors.New("rejected as invalid")
+	ErrServer      = errors.New("API server error")
+)
+
+// remediations suggest what a user can do about each kind of API error
+var remediations = map[error]string{
+	ErrRateLimited: "lower api.rate_limit.requests_per_second or raise api.retry.max_attempts",
+	ErrNotFound:    "check api.base_url and api.candidate_id; run 'megaverse init --candidate <id>' if the ID changed",
+	ErrConflict:    "the megaverse changed during the run; run 'megaverse reconcile' to converge on the goal",
+	ErrValidation:  "check the goal map or operation list for objects the API does not accept",
+	ErrServer:      "the API is failing; try again later or raise api.retry.max_attempts",
+}
+
+// APIError represents a detailed API error with status code. Message holds the message decoded from
+// the error body, or the raw body when it was not structured.
 type APIError struct {
 	StatusCode int
 	Message    string
 	Endpoint   string
+	// Code is the machine-readable error code from the body, when the API sent one
+	Code string
 }
 
 func (e *APIError) Error() string {
-	return e.Message
+	summary := fmt.Sprintf("API error (HTTP %d)", e.StatusCode)
+	if kind := e.Kind(); kind != nil {
+		summary = fmt.Sprintf("%s (HTTP %d)", kind, e.StatusCode)
+	}
+	if e.Message == "" {
+		return summary
+	}
+	return summary + ": " + e.Message
+}
+
+// Kind returns the sentinel error matching the status code, or nil for statuses without one
+func (e *APIError) Kind() error {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusConflict:
+		return ErrConflict
+	case e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity:
+		return ErrValidation
+	case e.StatusCode >= 500:
+		return ErrServer
+	}
+	return nil
+}
+
+// Unwrap exposes the kind of the error to errors.Is
+func (e *APIError) Unwrap() error {
+	return e.Kind()
+}
+
+// Hint suggests what the user can do about the error
+func (e *APIError) Hint() string {
+	if hint, ok := remediations[e.Kind()]; ok {
+		return hint
+	}
+	if e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden {
+		return "check api.candidate_id"
+	}
+	return ""
+}
+
+// Remediation returns the hint of the first API error in err's chain, or "" when there is none
+func Remediation(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Hint()
+	}
+	return ""
 }
 
 // NewAPIError creates a new API error
@@ -1,5 +1,12 @@
 package domain
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+)
+
 // GoalMap represents the target state of the megaverse.
 type GoalMap struct {
 	Goal [][]string `json:"goal"`
@@ -34,3 +41,84 @@ func ParseObjectType(goalString string) (objectType string, attributes map[strin
 		return "", nil
 	}
 }
+
+// ParseGoalCell converts a goal map token into the object it describes.
+// It returns a nil object for empty space and ok=false for tokens it does not recognise.
+func ParseGoalCell(value string, position entities.Position) (obj entities.AstralObject, ok bool) {
+	token := strings.ToUpper(strings.TrimSpace(value))
+	objectType, attributes := ParseObjectType(token)
+
+	switch objectType {
+	case "POLYANET":
+		return &entities.Polyanet{Position: position}, true
+	case "SOLOON":
+		return &entities.Soloon{Position: position, Color: entities.SoloonColor(attributes["color"])}, true
+	case "COMETH":
+		return &entities.Cometh{Position: position, Direction: entities.ComethDirection(attributes["direction"])}, true
+	}
+
+	switch token {
+	case "SPACE", "":
+		return nil, true
+	default:
+		return nil, false
+	}
+}
+
+// ToMegaverse builds the desired megaverse described by the goal map.
+// Unknown tokens are rejected so callers never act on a cell they do not understand.
+func (g *GoalMap) ToMegaverse() (*entities.Megaverse, error) {
+	height := len(g.Goal)
+	width := 0
+	if height > 0 {
+		width = len(g.Goal[0])
+	}
+
+	megaverse := entities.NewMegaverse(width, height)
+	for row, rowData := range g.Goal {
+		for col, value := range rowData {
+			obj, ok := ParseGoalCell(value, entities.Position{Row: row, Column: col})
+			if !ok {
+				return nil, fmt.Errorf("unknown goal cell %q at (%d, %d)", value, row, col)
+			}
+			if obj == nil {
+				continue
+			}
+			if err := megaverse.PlaceObject(obj); err != nil {
+				return nil, fmt.Errorf("goal cell at (%d, %d): %w", row, col, err)
+			}
+		}
+	}
+
+	return megaverse, nil
+}
+
+// GoalCellValue returns the goal map token for obj, the inverse of ParseGoalCell; nil is "SPACE"
+func GoalCellValue(obj entities.AstralObject) string {
+	switch o := obj.(type) {
+	case nil:
+		return "SPACE"
+	case *entities.Soloon:
+		return strings.ToUpper(string(o.Color)) + "_SOLOON"
+	case *entities.Cometh:
+		return strings.ToUpper(string(o.Direction)) + "_COMETH"
+	default:
+		return obj.GetType()
+	}
+}
+
+// Grid renders m as rows of goal map tokens, the shape used by GoalMap; nil yields nil
+func Grid(m *entities.Megaverse) [][]string {
+	if m == nil {
+		return nil
+	}
+	grid := make([][]string, m.Height)
+	for row := range grid {
+		grid[row] = make([]string, m.Width)
+		for col := range grid[row] {
+			obj, _ := m.GetObject(row, col)
+			grid[row][col] = GoalCellValue(obj)
+		}
+	}
+	return grid
+}
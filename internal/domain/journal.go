@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+)
+
+// Journal event names, in the order they occur during a run
+const (
+	JournalRunStarted    = "run.started"
+	JournalPlanGenerated = "plan.generated"
+	JournalObjectStarted = "object.started"
+	JournalObjectApplied = "object.applied"
+	JournalObjectFailed  = "object.failed"
+	JournalObjectSkipped = "object.skipped"
+	JournalRunFinished   = "run.finished"
+)
+
+// JournalEntry is one event in a run's journal. Objects are recorded as goal map tokens
+// (see GoalCellValue) so a journal can be replayed without the strategy that produced it.
+type JournalEntry struct {
+	Time        time.Time          `json:"time"`
+	RunID       string             `json:"run_id"`
+	OperationID string             `json:"op_id,omitempty"`
+	Event       string             `json:"event"`
+	Name        string             `json:"name,omitempty"`
+	Width       int                `json:"width,omitempty"`
+	Height      int                `json:"height,omitempty"`
+	Objects     int                `json:"objects,omitempty"` // plan size; -1 when the plan is streamed
+	Operation   string             `json:"operation,omitempty"`
+	Position    *entities.Position `json:"position,omitempty"`
+	Object      string             `json:"object,omitempty"`
+	Previous    string             `json:"previous,omitempty"`
+	Error       string             `json:"error,omitempty"`
+}
+
+// Journal records the events of a run in the order they happen
+type Journal interface {
+	// Append records entry; implementations must be safe for concurrent use
+	Append(entry JournalEntry) error
+}
@@ -0,0 +1,150 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+)
+
+// Kind identifies what an operation does to a cell
+type Kind int
+
+const (
+	// Create places an object in an empty cell
+	Create Kind = iota
+
+	// Delete removes the object occupying a cell
+	Delete
+
+	// Replace swaps the object occupying a cell for a different one
+	Replace
+)
+
+// String returns a human readable name for the kind
+func (k Kind) String() string {
+	switch k {
+	case Create:
+		return "create"
+	case Delete:
+		return "delete"
+	case Replace:
+		return "replace"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Operation is a single change to one cell of the megaverse. It records both the object it
+// removes and the object it places, so it can be inverted and composed without extra context.
+type Operation struct {
+	Kind     Kind
+	Position entities.Position
+
+	// Previous is the object removed by Delete and Replace; nil for Create
+	Previous entities.AstralObject
+
+	// Object is the object placed by Create and Replace; nil for Delete
+	Object entities.AstralObject
+}
+
+// NewCreate returns an operation that places obj
+func NewCreate(obj entities.AstralObject) Operation {
+	return Operation{Kind: Create, Position: obj.GetPosition(), Object: obj}
+}
+
+// NewDelete returns an operation that removes obj
+func NewDelete(obj entities.AstralObject) Operation {
+	return Operation{Kind: Delete, Position: obj.GetPosition(), Previous: obj}
+}
+
+// NewReplace returns an operation that swaps previous for obj
+func NewReplace(previous, obj entities.AstralObject) Operation {
+	return Operation{Kind: Replace, Position: obj.GetPosition(), Previous: previous, Object: obj}
+}
+
+// Between returns the operation that turns the cell at pos from before into after.
+// It returns false when the two states are the same and no operation is needed.
+func Between(pos entities.Position, before, after entities.AstralObject) (Operation, bool) {
+	switch {
+	case entities.SameObject(before, after):
+		return Operation{}, false
+	case before == nil:
+		return Operation{Kind: Create, Position: pos, Object: after}, true
+	case after == nil:
+		return Operation{Kind: Delete, Position: pos, Previous: before}, true
+	default:
+		return Operation{Kind: Replace, Position: pos, Previous: before, Object: after}, true
+	}
+}
+
+// Inverse returns the operation that undoes o
+func (o Operation) Inverse() Operation {
+	switch o.Kind {
+	case Create:
+		return Operation{Kind: Delete, Position: o.Position, Previous: o.Object}
+	case Delete:
+		return Operation{Kind: Create, Position: o.Position, Object: o.Previous}
+	default:
+		return Operation{Kind: Replace, Position: o.Position, Previous: o.Object, Object: o.Previous}
+	}
+}
+
+// Then composes o followed by next into a single operation on the same cell.
+// It returns false when the two cancel out, e.g. a create followed by a delete.
+func (o Operation) Then(next Operation) (Operation, bool, error) {
+	if o.Position != next.Position {
+		return Operation{}, false, fmt.Errorf("cannot compose operations on different cells (%d, %d) and (%d, %d)",
+			o.Position.Row, o.Position.Column, next.Position.Row, next.Position.Column)
+	}
+
+	// Only the state before o and the state after next matter; everything in between is redundant work.
+	composed, ok := Between(o.Position, o.Previous, next.Object)
+	return composed, ok, nil
+}
+
+// ApplyTo performs the operation on an in-memory megaverse
+func (o Operation) ApplyTo(m *entities.Megaverse) error {
+	if o.Object != nil {
+		return m.PlaceObject(o.Object)
+	}
+	if _, err := m.GetObject(o.Position.Row, o.Position.Column); err != nil {
+		return err
+	}
+	m.Grid[o.Position.Row][o.Position.Column] = nil
+	return nil
+}
+
+// String describes the operation, e.g. "replace SOLOON(red) with POLYANET at (2, 3)"
+func (o Operation) String() string {
+	switch o.Kind {
+	case Create:
+		return fmt.Sprintf("create %s at (%d, %d)", Describe(o.Object), o.Position.Row, o.Position.Column)
+	case Delete:
+		return fmt.Sprintf("delete %s at (%d, %d)", Describe(o.Previous), o.Position.Row, o.Position.Column)
+	default:
+		return fmt.Sprintf("replace %s with %s at (%d, %d)",
+			Describe(o.Previous), Describe(o.Object), o.Position.Row, o.Position.Column)
+	}
+}
+
+// Subject returns the object an operation is about: the one it places, or the one it deletes
+func (o Operation) Subject() entities.AstralObject {
+	if o.Object != nil {
+		return o.Object
+	}
+	return o.Previous
+}
+
+// Describe renders an object with its attributes, e.g. "SOLOON(red)"; nil renders as "SPACE"
+func Describe(obj entities.AstralObject) string {
+	switch o := obj.(type) {
+	case nil:
+		return "SPACE"
+	case *entities.Soloon:
+		return fmt.Sprintf("%s(%s)", o.GetType(), o.Color)
+	case *entities.Cometh:
+		return fmt.Sprintf("%s(%s)", o.GetType(), o.Direction)
+	default:
+		return o.GetType()
+	}
+}
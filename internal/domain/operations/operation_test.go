@@ -0,0 +1,84 @@
+package operations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+)
+
+var (
+	origin    = entities.Position{Row: 0, Column: 0}
+	neighbour = entities.Position{Row: 0, Column: 1}
+)
+
+func polyanet(pos entities.Position) entities.AstralObject {
+	return &entities.Polyanet{Position: pos}
+}
+
+func soloon(pos entities.Position, color entities.SoloonColor) entities.AstralObject {
+	return &entities.Soloon{Position: pos, Color: color}
+}
+
+func TestInverseUndoesOperation(t *testing.T) {
+	ops := []Operation{
+		NewCreate(polyanet(origin)),
+		NewReplace(soloon(neighbour, entities.RedSoloon), soloon(neighbour, entities.BlueSoloon)),
+	}
+
+	world := entities.NewMegaverse(2, 1)
+	require.NoError(t, world.PlaceObject(soloon(neighbour, entities.RedSoloon)))
+
+	for _, op := range ops {
+		require.NoError(t, op.ApplyTo(world))
+	}
+	for i := len(ops) - 1; i >= 0; i-- {
+		require.NoError(t, ops[i].Inverse().ApplyTo(world))
+	}
+
+	obj, err := world.GetObject(0, 0)
+	require.NoError(t, err)
+	require.Nil(t, obj)
+
+	obj, err = world.GetObject(0, 1)
+	require.NoError(t, err)
+	require.True(t, entities.SameObject(soloon(neighbour, entities.RedSoloon), obj))
+}
+
+func TestThenComposesOnSameCell(t *testing.T) {
+	create := NewCreate(polyanet(origin))
+
+	_, ok, err := create.Then(create.Inverse())
+	require.NoError(t, err)
+	require.False(t, ok, "create then delete should cancel out")
+
+	replaced, ok, err := NewDelete(polyanet(origin)).Then(NewCreate(soloon(origin, entities.WhiteSoloon)))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, Replace, replaced.Kind)
+	require.Equal(t, "replace POLYANET with SOLOON(white) at (0, 0)", replaced.String())
+
+	_, _, err = create.Then(NewCreate(polyanet(neighbour)))
+	require.Error(t, err)
+}
+
+func TestOptimizeRemovesRedundantWork(t *testing.T) {
+	ops := []Operation{
+		NewCreate(polyanet(origin)),
+		NewCreate(soloon(neighbour, entities.RedSoloon)),
+		NewDelete(polyanet(origin)),
+		NewReplace(soloon(neighbour, entities.RedSoloon), soloon(neighbour, entities.BlueSoloon)),
+		NewReplace(soloon(neighbour, entities.BlueSoloon), soloon(neighbour, entities.PurpleSoloon)),
+		NewCreate(polyanet(origin)),
+	}
+
+	optimized := Optimize(ops)
+	require.Len(t, optimized, 2)
+
+	require.Equal(t, Create, optimized[0].Kind)
+	require.Equal(t, origin, optimized[0].Position)
+
+	require.Equal(t, Create, optimized[1].Kind)
+	require.Equal(t, "create SOLOON(purple) at (0, 1)", optimized[1].String())
+}
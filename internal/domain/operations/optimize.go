@@ -0,0 +1,42 @@
+package operations
+
+import "github.com/crossmint/megaverse-challenge/internal/domain/entities"
+
+// Optimize reduces ops to the minimal list with the same effect. Operations on the same cell are
+// composed in order, which cancels create-then-delete pairs, collapses repeated writes, and turns
+// delete+create into a replace. Cells keep the order in which they were first touched.
+func Optimize(ops []Operation) []Operation {
+	order := make([]entities.Position, 0, len(ops))
+	net := make(map[entities.Position]*Operation, len(ops))
+
+	for _, op := range ops {
+		current, seen := net[op.Position]
+		if !seen {
+			order = append(order, op.Position)
+			op := op
+			net[op.Position] = &op
+			continue
+		}
+		if current == nil {
+			// Earlier operations cancelled out; this one starts afresh from the original state.
+			op := op
+			net[op.Position] = &op
+			continue
+		}
+
+		composed, ok, _ := current.Then(op)
+		if !ok {
+			net[op.Position] = nil
+			continue
+		}
+		net[op.Position] = &composed
+	}
+
+	optimized := make([]Operation, 0, len(order))
+	for _, pos := range order {
+		if op := net[pos]; op != nil {
+			optimized = append(optimized, *op)
+		}
+	}
+	return optimized
+}
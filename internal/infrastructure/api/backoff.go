@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// resetEpochThreshold separates X-RateLimit-Reset values given as Unix timestamps from values
+// given as seconds to wait; no server asks clients to wait for 30 years
+const resetEpochThreshold = 1_000_000_000
+
+// serverBackoff reads how long the server asked clients to wait before sending again. Retry-After
+// (seconds or an HTTP date) wins; otherwise X-RateLimit-Reset-After or X-RateLimit-Reset apply when
+// the response is a 429 or X-RateLimit-Remaining says the quota is spent.
+func serverBackoff(header http.Header, status int, now time.Time) (time.Duration, bool) {
+	if value := strings.TrimSpace(header.Get("Retry-After")); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+			return positive(time.Duration(seconds * float64(time.Second)))
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return positive(at.Sub(now))
+		}
+	}
+
+	exhausted := status == http.StatusTooManyRequests
+	if remaining := strings.TrimSpace(header.Get("X-RateLimit-Remaining")); remaining != "" {
+		if n, err := strconv.ParseFloat(remaining, 64); err == nil && n <= 0 {
+			exhausted = true
+		}
+	}
+	if !exhausted {
+		return 0, false
+	}
+
+	if value := strings.TrimSpace(header.Get("X-RateLimit-Reset-After")); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+			return positive(time.Duration(seconds * float64(time.Second)))
+		}
+	}
+	if value := strings.TrimSpace(header.Get("X-RateLimit-Reset")); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+			if seconds >= resetEpochThreshold {
+				return positive(time.Unix(0, int64(seconds*float64(time.Second))).Sub(now))
+			}
+			return positive(time.Duration(seconds * float64(time.Second)))
+		}
+	}
+	return 0, false
+}
+
+func positive(d time.Duration) (time.Duration, bool) {
+	if d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerBackoff(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+		want    time.Duration
+		hinted  bool
+	}{
+		{"retry-after seconds", map[string]string{"Retry-After": "3"}, 429, 3 * time.Second, true},
+		{"retry-after date", map[string]string{"Retry-After": now.Add(90 * time.Second).Format(http.TimeFormat)}, 503, 90 * time.Second, true},
+		{"retry-after in the past", map[string]string{"Retry-After": now.Add(-time.Minute).Format(http.TimeFormat)}, 429, 0, false},
+		{"reset after on 429", map[string]string{"X-RateLimit-Reset-After": "1.5"}, 429, 1500 * time.Millisecond, true},
+		{"reset epoch when exhausted", map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1714564810"}, 200, 10 * time.Second, true},
+		{"reset delta when exhausted", map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "4"}, 200, 4 * time.Second, true},
+		{"quota left", map[string]string{"X-RateLimit-Remaining": "7", "X-RateLimit-Reset": "4"}, 200, 0, false},
+		{"no headers", nil, 429, 0, false},
+		{"garbage", map[string]string{"Retry-After": "soon"}, 429, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.headers {
+				header.Set(key, value)
+			}
+			got, hinted := serverBackoff(header, tt.status, now)
+			require.Equal(t, tt.hinted, hinted)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
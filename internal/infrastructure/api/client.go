@@ -7,31 +7,70 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"time"
 
 	retry "github.com/avast/retry-go/v4"
 	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/pkg/audit"
+	"github.com/crossmint/megaverse-challenge/pkg/breaker"
+	"github.com/crossmint/megaverse-challenge/pkg/correlation"
+	"github.com/crossmint/megaverse-challenge/pkg/metrics"
 	"github.com/crossmint/megaverse-challenge/pkg/ratelimit"
 	pkgretry "github.com/crossmint/megaverse-challenge/pkg/retry"
+	"github.com/crossmint/megaverse-challenge/pkg/tracing"
 )
 
 // Client represents the HTTP client for the Megaverse API
 type Client struct {
-	baseURL     string
+	endpoints   *endpointPool
 	candidateID string
 	httpClient  *http.Client
+	timeout     time.Duration
+	attempt     time.Duration
 	rateLimiter *ratelimit.Limiter
 	retryConfig pkgretry.Config
+	logger      *slog.Logger
+	metrics     clientMetrics
+	tracer      *tracing.Tracer
+	observer    RequestObserver
+	auditLog    *audit.Log
+	breaker     *breaker.Breaker
+
+	debugHTTP      bool
+	debugBodyLimit int
 }
 
 // ClientConfig holds the configuration for the API client
 type ClientConfig struct {
 	BaseURL           string
+	BaseURLs          []string       // Tried in order, failing over on errors; BaseURL is used when empty
+	Failover          FailoverConfig // When to move off a failing base URL and when to try it again
 	CandidateID       string
-	Timeout           time.Duration
+	Timeout           time.Duration // Bounds a whole call, retries and backoff included
+	AttemptTimeout    time.Duration // Bounds each attempt; zero leaves attempts bounded by Timeout only
+	Transport         TransportConfig
 	RetryConfig       pkgretry.Config
 	RequestsPerSecond float64
+	Logger            *slog.Logger
+	Metrics           *metrics.Registry // Optional; nil disables instrumentation
+	Tracer            *tracing.Tracer   // Optional; nil disables tracing
+	Observer          RequestObserver   // Optional; called after every attempt
+	AuditLog          *audit.Log        // Optional; receives every attempt except GETs and HEADs
+
+	// Middlewares wrap the HTTP transport in order: the first sees each attempt first
+	Middlewares []Middleware
+
+	// CircuitBreaker fails requests fast while the API keeps failing; a zero FailureRatio disables it
+	CircuitBreaker breaker.Config
+	// OnCircuitChange is called after every circuit breaker state change when set
+	OnCircuitChange func(breaker.Transition)
+
+	// DebugHTTP logs every attempt's request and response, with the candidate ID redacted
+	DebugHTTP bool
+	// DebugBodyLimit caps logged bodies in bytes; zero uses 4096
+	DebugBodyLimit int
 }
 
 // NewClient creates a new API client
@@ -44,19 +83,68 @@ func NewClient(config ClientConfig) *Client {
 		config.RequestsPerSecond = 2.0
 	}
 
-	return &Client{
-		baseURL:     config.BaseURL,
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	if config.DebugBodyLimit <= 0 {
+		config.DebugBodyLimit = defaultDebugBodyLimit
+	}
+
+	baseURLs := config.BaseURLs
+	if len(baseURLs) == 0 {
+		baseURLs = []string{config.BaseURL}
+	}
+
+	client := &Client{
+		endpoints:   newEndpointPool(baseURLs, config.Failover, config.Logger),
 		candidateID: config.CandidateID,
 		httpClient: &http.Client{
-			Timeout: config.Timeout,
+			Transport: chain(newTransport(config.Transport), config.Middlewares),
 		},
+		timeout:     config.Timeout,
+		attempt:     config.AttemptTimeout,
 		rateLimiter: ratelimit.NewLimiter(config.RequestsPerSecond),
 		retryConfig: config.RetryConfig,
+		logger:      config.Logger,
+		metrics:     newClientMetrics(config.Metrics),
+		tracer:      config.Tracer,
+		observer:    config.Observer,
+		auditLog:    config.AuditLog,
+
+		debugHTTP:      config.DebugHTTP,
+		debugBodyLimit: config.DebugBodyLimit,
 	}
+	client.breaker = breaker.New(config.CircuitBreaker, func(t breaker.Transition) {
+		client.logCircuitChange(t)
+		if config.OnCircuitChange != nil {
+			config.OnCircuitChange(t)
+		}
+	})
+	return client
 }
 
-// doRequest performs an HTTP request with rate limiting and retry logic
-func (c *Client) doRequest(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
+// logCircuitChange reports a circuit breaker state change in the logs and metrics
+func (c *Client) logCircuitChange(t breaker.Transition) {
+	c.metrics.circuit.With().Set(float64(t.To))
+	switch t.To {
+	case breaker.Open:
+		c.logger.Warn("circuit breaker opened; failing API calls fast", "from", t.From.String(), "failures", t.Failures, "requests", t.Requests)
+	case breaker.HalfOpen:
+		c.logger.Info("circuit breaker half-open; probing the API")
+	case breaker.Closed:
+		c.logger.Info("circuit breaker closed; API calls resumed")
+	}
+}
+
+// AppliedFunc reports whether the effect of a request is already visible on the server
+type AppliedFunc func(ctx context.Context) (bool, error)
+
+// doRequest performs an HTTP request with rate limiting and retry logic. When applied is set, an
+// attempt that failed ambiguously (the request may have reached the server, but no response came
+// back) is checked with applied before the request is sent again; a confirmed request ends with a
+// nil response and no error. header adds request headers, such as conditional GET validators.
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, body interface{}, header http.Header, applied AppliedFunc) (*http.Response, error) {
 	var payload []byte
 	if body != nil {
 		var err error
@@ -66,27 +154,77 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body in
 		}
 	}
 
-	url := c.baseURL + endpoint
+	endpointLabel := c.endpointLabel(ctx, endpoint)
 	var resp *http.Response
+	attempt := 0
+	ambiguous, confirmed := false, false
+
+	// The call deadline must outlive doRequest: it is released when the caller closes the body.
+	ctx, cancelCall := withTimeout(ctx, c.timeout)
+
+	retryableErr := pkgretry.Do(ctx, func(ctx context.Context) (err error) {
+		attempt++
+		if ambiguous && c.confirmApplied(ctx, applied, method, endpointLabel) {
+			confirmed = true
+			return nil
+		}
+		ambiguous = false
+
+		if attempt > 1 {
+			c.logger.DebugContext(ctx, "retrying request", "method", method, "endpoint", endpointLabel, "attempt", attempt)
+			c.metrics.retries.With(endpointLabel, method).Inc()
+		}
+
+		ctx, span := c.tracer.Start(ctx, "http.attempt", tracing.KindClient,
+			tracing.String("http.method", method),
+			tracing.String("endpoint", endpointLabel),
+			tracing.Int("retry", attempt-1))
+		defer func() {
+			span.RecordError(err)
+			span.Finish()
+		}()
+
+		// Fail fast while the API is known to be down, before queueing on the limiter.
+		if err := c.breaker.Allow(); err != nil {
+			return retry.Unrecoverable(fmt.Errorf("%s %s not sent: %w", method, endpointLabel, err))
+		}
 
-	retryableErr := pkgretry.Do(ctx, func(ctx context.Context) error {
 		// Make every call synchronise on the limiter so bursts across goroutines keep a consistent pace.
+		waitStart := time.Now()
 		if err := c.rateLimiter.Wait(ctx); err != nil {
+			c.breaker.Abandon()
 			return retry.Unrecoverable(fmt.Errorf("rate limiter error: %w", err))
 		}
+		limiterWait := time.Since(waitStart)
+		c.metrics.observeLimiterWait(limiterWait)
+		span.SetAttributes(tracing.Float("limiter.wait_ms", float64(limiterWait.Microseconds())/1000))
 
 		var bodyReader io.Reader
 		if len(payload) > 0 {
 			bodyReader = bytes.NewReader(payload)
 		}
 
-		req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+		upstream := c.endpoints.pick()
+		span.SetAttributes(tracing.String("server.url", upstream.url))
+
+		attemptCtx, cancelAttempt := withTimeout(ctx, c.attempt)
+		attemptCtx = withRequestInfo(attemptCtx, requestInfo{endpoint: endpointLabel, attempt: attempt, candidateID: c.candidateID})
+		req, err := http.NewRequestWithContext(attemptCtx, method, upstream.url+endpoint, bodyReader)
 		if err != nil {
+			cancelAttempt()
+			c.breaker.Abandon()
+			c.endpoints.record(upstream, resultAbandoned)
 			return retry.Unrecoverable(fmt.Errorf("failed to create request: %w", err))
 		}
 
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Accept", "application/json")
+		if requestID := correlation.RequestID(ctx); requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
 
 		if resp != nil {
 			// The same response pointer is reused by retry-go; close the previous body before issuing another attempt.
@@ -94,24 +232,63 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body in
 			resp = nil
 		}
 
+		c.logHTTPRequest(ctx, req, payload, attempt)
+		record := RequestRecord{Start: time.Now(), LimiterWait: limiterWait, Method: method, BaseURL: upstream.url, Endpoint: endpointLabel, Attempt: attempt}
 		resp, err = c.httpClient.Do(req)
+		record.Duration = time.Since(record.Start)
+		if err != nil {
+			cancelAttempt()
+		} else {
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancelAttempt}
+		}
+		if ctx.Err() != nil {
+			// Our own cancellation says nothing about the API's health.
+			c.breaker.Abandon()
+			c.endpoints.record(upstream, resultAbandoned)
+		} else {
+			c.breaker.Record(err != nil || resp.StatusCode >= 500)
+			c.endpoints.record(upstream, attemptOutcome(resp, err))
+		}
 		if err != nil {
+			// Without a response we cannot tell whether the server acted on the request.
+			ambiguous = ctx.Err() == nil
+			c.logHTTPFailure(ctx, req, record.Duration, attempt, err)
+			record.Error = c.redact(err.Error())
+			c.recordAttempt(ctx, record, endpoint, payload)
+			c.metrics.observeRequest(endpointLabel, method, 0)
+			c.logger.WarnContext(ctx, "request failed", "method", method, "base_url", upstream.url, "endpoint", endpointLabel, "attempt", attempt, "error", c.redact(err.Error()))
 			return err
 		}
 
+		c.logHTTPResponse(ctx, resp, record.Duration, attempt)
 		status := resp.StatusCode
+		record.Status = status
+		c.recordAttempt(ctx, record, endpoint, payload)
+		c.metrics.observeRequest(endpointLabel, method, status)
+		span.SetAttributes(tracing.Int("http.status_code", status))
+		c.logger.DebugContext(ctx, "request completed", "method", method, "base_url", upstream.url, "endpoint", endpointLabel, "attempt", attempt, "status", status)
+
+		backoff, hinted := serverBackoff(resp.Header, status, time.Now())
+		if hinted {
+			// Pause the shared limiter so every worker backs off, not just the one that was told to.
+			c.rateLimiter.Pause(backoff)
+			span.SetAttributes(tracing.Float("server.backoff_ms", float64(backoff.Milliseconds())))
+			c.logger.WarnContext(ctx, "server requested backoff", "method", method, "endpoint", endpointLabel, "status", status, "delay", backoff)
+		}
+
 		if status == http.StatusTooManyRequests || status >= 500 {
 			responseBody, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
 			resp = nil
-			return domain.NewAPIError(status, string(responseBody), endpoint)
+			c.logger.WarnContext(ctx, "retryable response", "method", method, "base_url", upstream.url, "endpoint", endpointLabel, "attempt", attempt, "status", status)
+			return pkgretry.After(newAPIError(status, responseBody, endpoint), backoff)
 		}
 
 		if status >= 400 {
 			responseBody, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
 			resp = nil
-			return retry.Unrecoverable(domain.NewAPIError(status, string(responseBody), endpoint))
+			return retry.Unrecoverable(newAPIError(status, responseBody, endpoint))
 		}
 
 		return nil
@@ -127,50 +304,95 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body in
 
 		var apiErr *domain.APIError
 		if errors.As(err, &apiErr) {
-			return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+			return errors.Is(apiErr, domain.ErrRateLimited) || errors.Is(apiErr, domain.ErrServer)
 		}
 
 		return true
 	})
 
 	if retryableErr != nil {
+		defer cancelCall()
+		if ambiguous && c.confirmApplied(ctx, applied, method, endpointLabel) {
+			return nil, nil
+		}
 		return nil, retryableErr
 	}
 
+	if confirmed {
+		cancelCall()
+		return nil, nil
+	}
 	if resp == nil {
+		cancelCall()
 		return nil, fmt.Errorf("no response received from %s %s", method, endpoint)
 	}
 
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancelCall}
 	return resp, nil
 }
 
-// Post performs a POST request
-func (c *Client) Post(ctx context.Context, endpoint string, body interface{}) error {
-	resp, err := c.doRequest(ctx, http.MethodPost, endpoint, body)
+// confirmApplied asks applied whether an ambiguously failed request took effect. Errors count as
+// not applied, so the request is retried as it would have been without the check.
+func (c *Client) confirmApplied(ctx context.Context, applied AppliedFunc, method, endpointLabel string) bool {
+	if applied == nil || ctx.Err() != nil {
+		return false
+	}
+	done, err := applied(ctx)
 	if err != nil {
-		return err
+		c.logger.WarnContext(ctx, "could not verify request after ambiguous failure", "method", method, "endpoint", endpointLabel, "error", c.redact(err.Error()))
+		return false
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return domain.NewAPIError(resp.StatusCode, string(body), endpoint)
+	if done {
+		c.logger.InfoContext(ctx, "request already applied; not sending it again", "method", method, "endpoint", endpointLabel)
 	}
+	return done
+}
 
-	return nil
+// Post performs a POST request
+func (c *Client) Post(ctx context.Context, endpoint string, body interface{}) error {
+	return c.send(ctx, http.MethodPost, endpoint, body, nil)
+}
+
+// PostIdempotent performs a POST whose effect applied can confirm. Ambiguous failures are checked
+// with applied before retrying, and a conflict response counts as success when applied confirms
+// the server already holds what was asked for.
+func (c *Client) PostIdempotent(ctx context.Context, endpoint string, body interface{}, applied AppliedFunc) error {
+	return c.SendIdempotent(ctx, http.MethodPost, endpoint, body, applied)
 }
 
 // Delete performs a DELETE request
 func (c *Client) Delete(ctx context.Context, endpoint string, body interface{}) error {
-	resp, err := c.doRequest(ctx, http.MethodDelete, endpoint, body)
+	return c.send(ctx, http.MethodDelete, endpoint, body, nil)
+}
+
+// Send performs a request with any method and expects a 2xx response
+func (c *Client) Send(ctx context.Context, method, endpoint string, body interface{}) error {
+	return c.send(ctx, method, endpoint, body, nil)
+}
+
+// SendIdempotent is PostIdempotent for any method
+func (c *Client) SendIdempotent(ctx context.Context, method, endpoint string, body interface{}, applied AppliedFunc) error {
+	err := c.send(ctx, method, endpoint, body, applied)
+	if errors.Is(err, domain.ErrConflict) && c.confirmApplied(ctx, applied, method, c.endpointLabel(ctx, endpoint)) {
+		return nil
+	}
+	return err
+}
+
+func (c *Client) send(ctx context.Context, method, endpoint string, body interface{}, applied AppliedFunc) error {
+	resp, err := c.doRequest(ctx, method, endpoint, body, nil, applied)
 	if err != nil {
 		return err
 	}
+	if resp == nil {
+		// applied confirmed the request after an ambiguous failure
+		return nil
+	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return domain.NewAPIError(resp.StatusCode, string(body), endpoint)
+		return newAPIError(resp.StatusCode, body, endpoint)
 	}
 
 	return nil
@@ -178,27 +400,57 @@ func (c *Client) Delete(ctx context.Context, endpoint string, body interface{})
 
 // Get performs a GET request and unmarshals the response
 func (c *Client) Get(ctx context.Context, endpoint string, result interface{}) error {
-	resp, err := c.doRequest(ctx, http.MethodGet, endpoint, nil)
+	_, _, err := c.GetIfChanged(ctx, endpoint, Validators{}, result)
+	return err
+}
+
+// Validators identify a cached response for conditional GETs
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// GetIfChanged performs a GET that the server may answer with 304 Not Modified while cached still
+// describes the resource. Otherwise it unmarshals the response into result and returns the
+// response's validators.
+func (c *Client) GetIfChanged(ctx context.Context, endpoint string, cached Validators, result interface{}) (Validators, bool, error) {
+	header := http.Header{}
+	if cached.ETag != "" {
+		header.Set("If-None-Match", cached.ETag)
+	}
+	if cached.LastModified != "" {
+		header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, endpoint, nil, header, nil)
 	if err != nil {
-		return err
+		return Validators{}, false, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != (Validators{}) {
+		return cached, true, nil
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return domain.NewAPIError(resp.StatusCode, string(body), endpoint)
+		return Validators{}, false, newAPIError(resp.StatusCode, body, endpoint)
 	}
 
 	if result != nil {
 		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+			return Validators{}, false, fmt.Errorf("failed to decode response: %w", err)
 		}
 	}
 
-	return nil
+	return Validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}, false, nil
 }
 
 // GetCandidateID returns the configured candidate ID
 func (c *Client) GetCandidateID() string {
 	return c.candidateID
 }
+
+// BaseURL returns the primary base URL; failover mirrors are expected to serve the same API
+func (c *Client) BaseURL() string {
+	return c.endpoints.upstreams[0].url
+}
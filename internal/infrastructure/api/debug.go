@@ -0,0 +1,75 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// defaultDebugBodyLimit caps logged bodies when ClientConfig.DebugBodyLimit is unset
+const defaultDebugBodyLimit = 4096
+
+// logHTTPRequest logs an outgoing attempt in full when HTTP debugging is enabled
+func (c *Client) logHTTPRequest(ctx context.Context, req *http.Request, payload []byte, attempt int) {
+	if !c.debugHTTP {
+		return
+	}
+	c.logger.InfoContext(ctx, "http request",
+		"method", req.Method,
+		"url", c.redact(req.URL.String()),
+		"attempt", attempt,
+		"headers", req.Header,
+		"body", c.debugBody(payload))
+}
+
+// logHTTPResponse logs the response to an attempt in full when HTTP debugging is enabled. The body
+// is read and replaced with an in-memory copy so callers can still consume it.
+func (c *Client) logHTTPResponse(ctx context.Context, resp *http.Response, elapsed time.Duration, attempt int) {
+	if !c.debugHTTP {
+		return
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	fields := []any{
+		"method", resp.Request.Method,
+		"url", c.redact(resp.Request.URL.String()),
+		"attempt", attempt,
+		"status", resp.StatusCode,
+		"duration", elapsed,
+		"headers", resp.Header,
+		"body", c.debugBody(body),
+	}
+	if err != nil {
+		fields = append(fields, "error", c.redact(err.Error()))
+	}
+	c.logger.InfoContext(ctx, "http response", fields...)
+}
+
+// logHTTPFailure logs an attempt that produced no response when HTTP debugging is enabled
+func (c *Client) logHTTPFailure(ctx context.Context, req *http.Request, elapsed time.Duration, attempt int, err error) {
+	if !c.debugHTTP {
+		return
+	}
+	c.logger.InfoContext(ctx, "http response",
+		"method", req.Method,
+		"url", c.redact(req.URL.String()),
+		"attempt", attempt,
+		"duration", elapsed,
+		"error", c.redact(err.Error()))
+}
+
+// debugBody redacts the candidate ID and then truncates body to the configured limit, so a cut
+// never leaves part of the ID behind
+func (c *Client) debugBody(body []byte) string {
+	text := c.redact(string(body))
+	if len(text) <= c.debugBodyLimit {
+		return text
+	}
+	return fmt.Sprintf("%s... (%d more bytes)", text[:c.debugBodyLimit], len(text)-c.debugBodyLimit)
+}
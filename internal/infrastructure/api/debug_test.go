@@ -0,0 +1,50 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pkgretry "github.com/crossmint/megaverse-challenge/pkg/retry"
+)
+
+func TestDebugHTTPRedactsAndTruncates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Served-By", "test")
+		_, _ = w.Write([]byte(`{"goal":"` + strings.Repeat("x", 100) + `"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var logs bytes.Buffer
+	client := NewClient(ClientConfig{
+		BaseURL:           server.URL,
+		CandidateID:       "secret-candidate",
+		Timeout:           time.Second,
+		RetryConfig:       pkgretry.Config{MaxAttempts: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1},
+		RequestsPerSecond: 100,
+		Logger:            slog.New(slog.NewTextHandler(&logs, nil)),
+		DebugHTTP:         true,
+		DebugBodyLimit:    40,
+	})
+
+	var result map[string]string
+	require.NoError(t, client.Get(context.Background(), "/map/secret-candidate/goal", &result))
+	require.Len(t, result["goal"], 100, "the response body must still reach the caller")
+
+	require.NoError(t, client.Post(context.Background(), "/polyanets", CreatePolyanetRequest{Row: 1, Column: 2, CandidateID: "secret-candidate"}))
+
+	out := logs.String()
+	require.NotContains(t, out, "secret-candidate")
+	require.Contains(t, out, "/map/{candidateId}/goal")
+	require.Contains(t, out, `{\"row\":1,\"column\":2,\"candidateId\":\"{cand... (10 more bytes)`)
+	require.Contains(t, out, "X-Served-By")
+	require.Contains(t, out, "more bytes")
+	require.Contains(t, out, "duration=")
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NoBody as an Endpoint body sends the request without one; an empty body keeps the default
+const NoBody = "none"
+
+// Endpoint is a templated API request. Path and Body may reference placeholders such as {row};
+// Body is a JSON document or NoBody.
+type Endpoint struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// ObjectEndpoints are the requests that create and delete one type of astral object
+type ObjectEndpoints struct {
+	Create Endpoint
+	Delete Endpoint
+}
+
+// Endpoints describes the paths, methods, and bodies of the megaverse API relative to the base URL,
+// so forks, staging mirrors, or versioned APIs can be targeted without code changes.
+//
+// Templates may use {candidateId}, {row}, {column}, and {type} (the lower-case object type), and
+// object creates also {color} and {direction}. In bodies, {row} and {column} expand to numbers and
+// every value is JSON-escaped, so `{"row": {row}, "color": "{color}"}` is a valid template.
+type Endpoints struct {
+	GoalMap    string // GET path of the goal map
+	CurrentMap string // GET path of the live map
+	// Objects maps an object type such as "POLYANET" to its requests
+	Objects map[string]ObjectEndpoints
+}
+
+const (
+	defaultPositionBody = `{"row": {row}, "column": {column}, "candidateId": "{candidateId}"}`
+	defaultSoloonBody   = `{"row": {row}, "column": {column}, "color": "{color}", "candidateId": "{candidateId}"}`
+	defaultComethBody   = `{"row": {row}, "column": {column}, "direction": "{direction}", "candidateId": "{candidateId}"}`
+)
+
+// DefaultEndpoints returns the endpoints of the Crossmint challenge API
+func DefaultEndpoints() Endpoints {
+	object := func(path, createBody string) ObjectEndpoints {
+		return ObjectEndpoints{
+			Create: Endpoint{Method: http.MethodPost, Path: path, Body: createBody},
+			Delete: Endpoint{Method: http.MethodDelete, Path: path, Body: defaultPositionBody},
+		}
+	}
+	return Endpoints{
+		GoalMap:    "/map/{candidateId}/goal",
+		CurrentMap: "/map/{candidateId}",
+		Objects: map[string]ObjectEndpoints{
+			"POLYANET": object("/polyanets", defaultPositionBody),
+			"SOLOON":   object("/soloons", defaultSoloonBody),
+			"COMETH":   object("/comeths", defaultComethBody),
+		},
+	}
+}
+
+// WithDefaults fills every field e leaves empty from DefaultEndpoints, so a configuration can
+// override single paths. Object types are matched case-insensitively.
+func (e Endpoints) WithDefaults() Endpoints {
+	defaults := DefaultEndpoints()
+	merged := Endpoints{
+		GoalMap:    orDefault(e.GoalMap, defaults.GoalMap),
+		CurrentMap: orDefault(e.CurrentMap, defaults.CurrentMap),
+		Objects:    defaults.Objects,
+	}
+	for objectType, custom := range e.Objects {
+		objectType = strings.ToUpper(objectType)
+		base := merged.Objects[objectType]
+		merged.Objects[objectType] = ObjectEndpoints{
+			Create: custom.Create.withDefaults(base.Create),
+			Delete: custom.Delete.withDefaults(base.Delete),
+		}
+	}
+	return merged
+}
+
+func (e Endpoint) withDefaults(defaults Endpoint) Endpoint {
+	return Endpoint{
+		Method: strings.ToUpper(orDefault(e.Method, defaults.Method)),
+		Path:   orDefault(e.Path, defaults.Path),
+		Body:   orDefault(e.Body, defaults.Body),
+	}
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+var (
+	placeholder = regexp.MustCompile(`\{([A-Za-z]+)\}`)
+	methodToken = regexp.MustCompile(`^[A-Z]+$`)
+)
+
+// numericVars expand to JSON numbers in bodies
+var numericVars = map[string]bool{"row": true, "column": true}
+
+// Validate checks that every template only uses placeholders available to it and that bodies
+// expand to valid JSON
+func (e Endpoints) Validate() error {
+	mapVars := map[string]string{"candidateId": "id"}
+	for name, path := range map[string]string{"goal map": e.GoalMap, "current map": e.CurrentMap} {
+		if _, err := expand(path, mapVars, false); err != nil {
+			return fmt.Errorf("%s path: %w", name, err)
+		}
+	}
+
+	types := make([]string, 0, len(e.Objects))
+	for objectType := range e.Objects {
+		types = append(types, objectType)
+	}
+	sort.Strings(types)
+
+	for _, objectType := range types {
+		object := e.Objects[objectType]
+		deleteVars := objectVars("id", strings.ToLower(objectType), 0, 0)
+		createVars := objectVars("id", strings.ToLower(objectType), 0, 0)
+		createVars["color"], createVars["direction"] = "", ""
+
+		if err := object.Create.validate(createVars); err != nil {
+			return fmt.Errorf("%s create: %w", strings.ToLower(objectType), err)
+		}
+		if err := object.Delete.validate(deleteVars); err != nil {
+			return fmt.Errorf("%s delete: %w", strings.ToLower(objectType), err)
+		}
+	}
+	return nil
+}
+
+func (e Endpoint) validate(vars map[string]string) error {
+	if !methodToken.MatchString(e.Method) {
+		return fmt.Errorf("invalid method %q", e.Method)
+	}
+	if _, err := expand(e.Path, vars, false); err != nil {
+		return fmt.Errorf("path: %w", err)
+	}
+	if _, err := e.body(vars); err != nil {
+		return err
+	}
+	return nil
+}
+
+// body expands the body template with vars, or returns nil when there is none
+func (e Endpoint) body(vars map[string]string) (json.RawMessage, error) {
+	if body := strings.TrimSpace(e.Body); body == "" || body == NoBody {
+		return nil, nil
+	}
+	body, err := expand(e.Body, vars, true)
+	if err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+	if !json.Valid([]byte(body)) {
+		return nil, fmt.Errorf("body is not valid JSON once expanded: %s", body)
+	}
+	return json.RawMessage(body), nil
+}
+
+// objectVars returns the placeholders shared by every object request
+func objectVars(candidateID, objectType string, row, column int) map[string]string {
+	return map[string]string{
+		"candidateId": candidateID,
+		"type":        objectType,
+		"row":         strconv.Itoa(row),
+		"column":      strconv.Itoa(column),
+	}
+}
+
+// expand replaces the placeholders of template with vars. In JSON bodies, values are escaped and
+// numeric placeholders are left bare.
+func expand(template string, vars map[string]string, jsonBody bool) (string, error) {
+	var missing []string
+	expanded := placeholder.ReplaceAllStringFunc(template, func(match string) string {
+		name := match[1 : len(match)-1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, match)
+			return match
+		}
+		if jsonBody && !numericVars[name] {
+			quoted, _ := json.Marshal(value)
+			return string(quoted[1 : len(quoted)-1])
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("unknown placeholder %s in %q", strings.Join(missing, ", "), template)
+	}
+	return expanded, nil
+}
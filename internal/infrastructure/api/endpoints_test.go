@@ -0,0 +1,157 @@
+package api_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/api"
+	"github.com/crossmint/megaverse-challenge/pkg/audit"
+	pkgretry "github.com/crossmint/megaverse-challenge/pkg/retry"
+)
+
+type sentRequest struct {
+	method, path, body string
+}
+
+func TestRepositoryUsesEndpointTemplates(t *testing.T) {
+	var mu sync.Mutex
+	var sent []sentRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		sent = append(sent, sentRequest{r.Method, r.URL.Path, string(body)})
+		mu.Unlock()
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"goal":[["SPACE"]]}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	endpoints := api.Endpoints{
+		GoalMap: "/v2/candidates/{candidateId}/goal",
+		Objects: map[string]api.ObjectEndpoints{
+			"soloon": {
+				Create: api.Endpoint{Method: "put", Path: "/v2/objects/{type}", Body: `{"cell": [{row}, {column}], "attributes": {"color": "{color}"}, "owner": "{candidateId}"}`},
+				Delete: api.Endpoint{Method: "POST", Path: "/v2/objects/{type}/{row}/{column}/delete", Body: api.NoBody},
+			},
+		},
+	}.WithDefaults()
+	require.NoError(t, endpoints.Validate())
+	repo := api.NewRepository(newTestClient(server.URL)).WithEndpoints(endpoints)
+
+	ctx := context.Background()
+	require.NoError(t, repo.CreateSoloon(ctx, entities.Position{Row: 1, Column: 2}, entities.RedSoloon))
+	require.NoError(t, repo.DeleteObject(ctx, "SOLOON", entities.Position{Row: 3, Column: 4}))
+	require.NoError(t, repo.CreatePolyanet(ctx, entities.Position{Row: 5, Column: 6}))
+	_, err := repo.GetGoalMap(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, []sentRequest{
+		{http.MethodPut, "/v2/objects/soloon", `{"cell":[1,2],"attributes":{"color":"red"},"owner":"test-id"}`},
+		{http.MethodPost, "/v2/objects/soloon/3/4/delete", ""},
+		{http.MethodPost, "/polyanets", `{"row":5,"column":6,"candidateId":"test-id"}`},
+		{http.MethodGet, "/v2/candidates/test-id/goal", ""},
+	}, sent)
+}
+
+func TestAuditRecordsCellsOfTemplatedRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := audit.Open(path)
+	require.NoError(t, err)
+
+	endpoints := api.Endpoints{Objects: map[string]api.ObjectEndpoints{
+		"polyanet": {Delete: api.Endpoint{Path: "/polyanets/{row}/{column}", Body: api.NoBody}},
+	}}.WithDefaults()
+	repo := api.NewRepository(api.NewClient(api.ClientConfig{
+		BaseURL:           server.URL,
+		CandidateID:       "test-id",
+		Timeout:           time.Second,
+		RetryConfig:       pkgretry.Config{MaxAttempts: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1},
+		RequestsPerSecond: 100,
+		AuditLog:          auditLog,
+	})).WithEndpoints(endpoints)
+
+	require.NoError(t, repo.DeleteObject(context.Background(), "POLYANET", entities.Position{Row: 3, Column: 4}))
+	require.NoError(t, auditLog.Close())
+
+	entries, err := audit.Read(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Empty(t, entries[0].Body)
+	require.Equal(t, "/polyanets/3/4", entries[0].Endpoint, "the audit log keeps the expanded path")
+	require.Equal(t, &audit.Cell{Row: 3, Column: 4}, entries[0].Cell)
+}
+
+func TestRequestsAreLabelledWithTheirTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"map": {"content": [[null, null]]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	var mu sync.Mutex
+	var labels []string
+	repo := api.NewRepository(api.NewClient(api.ClientConfig{
+		BaseURL:           server.URL,
+		CandidateID:       "test-id",
+		Timeout:           time.Second,
+		RetryConfig:       pkgretry.Config{MaxAttempts: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1},
+		RequestsPerSecond: 100,
+		Observer: func(record api.RequestRecord) {
+			mu.Lock()
+			defer mu.Unlock()
+			labels = append(labels, record.Endpoint)
+		},
+	})).WithEndpoints(api.Endpoints{Objects: map[string]api.ObjectEndpoints{
+		"polyanet": {Delete: api.Endpoint{Path: "/polyanets/{row}/{column}", Body: api.NoBody}},
+	}}.WithDefaults())
+
+	ctx := context.Background()
+	require.NoError(t, repo.DeleteObject(ctx, "POLYANET", entities.Position{Row: 1, Column: 2}))
+	require.NoError(t, repo.DeleteObject(ctx, "POLYANET", entities.Position{Row: 3, Column: 4}))
+	_, err := repo.GetCurrentMap(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"/polyanets/{row}/{column}", "/polyanets/{row}/{column}", "/map/{candidateId}"}, labels)
+}
+
+func TestEndpointsValidate(t *testing.T) {
+	require.NoError(t, api.DefaultEndpoints().Validate())
+
+	tests := map[string]api.Endpoints{
+		"unknown placeholder": {GoalMap: "/map/{candidate}/goal"},
+		"color in a delete": {Objects: map[string]api.ObjectEndpoints{
+			"soloon": {Delete: api.Endpoint{Body: `{"color": "{color}"}`}},
+		}},
+		"invalid JSON body": {Objects: map[string]api.ObjectEndpoints{
+			"polyanet": {Create: api.Endpoint{Body: `{"row": {row},}`}},
+		}},
+		"invalid method": {Objects: map[string]api.ObjectEndpoints{
+			"cometh": {Create: api.Endpoint{Method: "CREATE NOW"}},
+		}},
+	}
+	for name, endpoints := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, endpoints.WithDefaults().Validate())
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+)
+
+// newAPIError decodes an error body into a structured API error. Bodies that are not an
+// ErrorResponse are kept verbatim as the message.
+func newAPIError(status int, body []byte, endpoint string) *domain.APIError {
+	message := strings.TrimSpace(string(body))
+
+	var response ErrorResponse
+	if json.Unmarshal(body, &response) != nil {
+		return domain.NewAPIError(status, message, endpoint)
+	}
+
+	var code string
+	_ = json.Unmarshal(response.Error, &code)
+
+	switch {
+	case response.Message != "":
+		message = response.Message
+	case response.Reason != "":
+		message = response.Reason
+	case code != "":
+		message = code
+	}
+
+	apiErr := domain.NewAPIError(status, message, endpoint)
+	apiErr.Code = code
+	return apiErr
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+)
+
+func TestNewAPIErrorDecodesBodies(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		kind    error
+		message string
+		code    string
+	}{
+		{"reason", 429, `{"error":true,"reason":"Too Many Requests. Please try again later."}`, domain.ErrRateLimited, "Too Many Requests. Please try again later.", ""},
+		{"message and code", 422, `{"error":"invalid_color","message":"color must be one of blue, red, purple, white"}`, domain.ErrValidation, "color must be one of blue, red, purple, white", "invalid_color"},
+		{"code only", 409, `{"error":"occupied"}`, domain.ErrConflict, "occupied", "occupied"},
+		{"plain text", 502, "Bad Gateway\n", domain.ErrServer, "Bad Gateway", ""},
+		{"not found", 404, "", domain.ErrNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := newAPIError(tt.status, []byte(tt.body), "/polyanets")
+			require.Equal(t, tt.message, apiErr.Message)
+			require.Equal(t, tt.code, apiErr.Code)
+
+			wrapped := fmt.Errorf("create polyanet: %w", apiErr)
+			require.ErrorIs(t, wrapped, tt.kind)
+			require.NotEmpty(t, domain.Remediation(wrapped))
+		})
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	err := newAPIError(429, []byte(`{"error":true,"reason":"slow down"}`), "/polyanets")
+	require.Equal(t, "rate limited by the API (HTTP 429): slow down", err.Error())
+	require.Contains(t, err.Hint(), "api.rate_limit.requests_per_second")
+
+	err = newAPIError(418, nil, "/polyanets")
+	require.Equal(t, "API error (HTTP 418)", err.Error())
+	require.False(t, errors.Is(err, domain.ErrServer))
+	require.Empty(t, domain.Remediation(err))
+}
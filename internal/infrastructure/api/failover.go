@@ -0,0 +1,135 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FailoverConfig tunes when a client moves off a base URL and when it tries it again
+type FailoverConfig struct {
+	// Failures is how many 5xx responses in a row take a base URL out of rotation; zero uses 3.
+	// Connection errors take it out at once.
+	Failures int
+	// Cooldown is how long a base URL stays out of rotation before a single request probes it;
+	// zero uses 30s
+	Cooldown time.Duration
+}
+
+// attemptResult is how an attempt went, as far as the health of its base URL is concerned
+type attemptResult int
+
+const (
+	resultOK attemptResult = iota
+	resultServerError
+	resultConnError
+	// resultAbandoned ends an attempt that says nothing about the base URL, such as a cancelled one
+	resultAbandoned
+)
+
+// attemptOutcome classifies the response or transport error of an attempt
+func attemptOutcome(resp *http.Response, err error) attemptResult {
+	switch {
+	case err != nil:
+		return resultConnError
+	case resp.StatusCode >= 500:
+		return resultServerError
+	}
+	return resultOK
+}
+
+// upstream is one base URL and its health
+type upstream struct {
+	url       string
+	failures  int // consecutive 5xx responses
+	down      bool
+	downUntil time.Time
+	probing   bool
+}
+
+// endpointPool hands out base URLs in configured order, skipping those that recently failed. A
+// base URL that is out of rotation gets a single probe request once its cooldown has passed, and
+// is used again when the probe succeeds. It is safe for concurrent use.
+type endpointPool struct {
+	config FailoverConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu        sync.Mutex
+	upstreams []*upstream
+}
+
+func newEndpointPool(urls []string, config FailoverConfig, logger *slog.Logger) *endpointPool {
+	if config.Failures <= 0 {
+		config.Failures = 3
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 30 * time.Second
+	}
+	pool := &endpointPool{config: config, logger: logger, now: time.Now}
+	for _, url := range urls {
+		pool.upstreams = append(pool.upstreams, &upstream{url: url})
+	}
+	return pool
+}
+
+// pick returns the base URL for the next attempt: the first healthy one, or one whose cooldown
+// has passed and that is not already being probed. When every base URL is out of rotation, the
+// one that has been down the longest is used anyway.
+func (p *endpointPool) pick() *upstream {
+	if len(p.upstreams) == 1 {
+		return p.upstreams[0]
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var fallback *upstream
+	for _, u := range p.upstreams {
+		if !u.down {
+			return u
+		}
+		if !u.probing && !now.Before(u.downUntil) {
+			u.probing = true
+			return u
+		}
+		if fallback == nil || u.downUntil.Before(fallback.downUntil) {
+			fallback = u
+		}
+	}
+	return fallback
+}
+
+// record reports how an attempt against u went
+func (p *endpointPool) record(u *upstream, result attemptResult) {
+	if len(p.upstreams) == 1 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u.probing = false
+
+	switch result {
+	case resultOK:
+		u.failures = 0
+		if u.down {
+			u.down = false
+			p.logger.Info("API endpoint healthy again; back in rotation", "base_url", u.url)
+		}
+	case resultServerError, resultConnError:
+		u.failures++
+		if u.down {
+			u.downUntil = p.now().Add(p.config.Cooldown)
+			return
+		}
+		if result == resultConnError || u.failures >= p.config.Failures {
+			u.down = true
+			u.downUntil = p.now().Add(p.config.Cooldown)
+			p.logger.Warn("API endpoint failing; failing over", "base_url", u.url, "failures", u.failures, "retry_in", p.config.Cooldown)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pkgretry "github.com/crossmint/megaverse-challenge/pkg/retry"
+)
+
+func TestEndpointPoolFailsOverAndBack(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	pool := newEndpointPool([]string{"primary", "mirror"}, FailoverConfig{Failures: 2, Cooldown: time.Minute}, slog.Default())
+	pool.now = func() time.Time { return now }
+
+	primary := pool.pick()
+	require.Equal(t, "primary", primary.url)
+
+	pool.record(primary, resultServerError)
+	require.Equal(t, "primary", pool.pick().url, "a single 5xx keeps the base URL in rotation")
+	pool.record(primary, resultOK)
+	pool.record(primary, resultServerError)
+	require.Equal(t, "primary", pool.pick().url, "a success resets the 5xx count")
+	pool.record(primary, resultServerError)
+	require.Equal(t, "mirror", pool.pick().url)
+
+	now = now.Add(time.Minute)
+	probe := pool.pick()
+	require.Equal(t, "primary", probe.url, "the cooldown has passed, so the primary is probed")
+	require.Equal(t, "mirror", pool.pick().url, "only one request probes at a time")
+
+	pool.record(probe, resultConnError)
+	require.Equal(t, "mirror", pool.pick().url, "a failed probe restarts the cooldown")
+
+	now = now.Add(time.Minute)
+	probe = pool.pick()
+	require.Equal(t, "primary", probe.url)
+	pool.record(probe, resultOK)
+	require.Equal(t, "primary", pool.pick().url, "a successful probe fails back")
+}
+
+func TestEndpointPoolConnectionErrorFailsOverAtOnce(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	pool := newEndpointPool([]string{"primary", "mirror"}, FailoverConfig{Failures: 5, Cooldown: time.Minute}, slog.Default())
+	pool.now = func() time.Time { return now }
+
+	pool.record(pool.pick(), resultConnError)
+	mirror := pool.pick()
+	require.Equal(t, "mirror", mirror.url)
+
+	now = now.Add(10 * time.Second)
+	pool.record(mirror, resultConnError)
+	require.Equal(t, "primary", pool.pick().url, "with every base URL down, the one down longest is used")
+
+	pool.record(pool.pick(), resultAbandoned)
+	require.Equal(t, "primary", pool.pick().url)
+}
+
+func TestClientFailsOverAndBack(t *testing.T) {
+	var primaryFailing atomic.Bool
+	primaryFailing.Store(true)
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if primaryFailing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(goalBody))
+	}))
+	defer primary.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(goalBody))
+	}))
+	defer mirror.Close()
+
+	var mu sync.Mutex
+	var served []string
+	client := NewClient(ClientConfig{
+		BaseURLs:    []string{primary.URL, mirror.URL},
+		Failover:    FailoverConfig{Failures: 2, Cooldown: 50 * time.Millisecond},
+		CandidateID: "test-id",
+		RetryConfig: pkgretry.Config{
+			MaxAttempts:  3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Multiplier:   1.0,
+		},
+		RequestsPerSecond: 100,
+		Observer: func(record RequestRecord) {
+			mu.Lock()
+			defer mu.Unlock()
+			served = append(served, record.BaseURL)
+		},
+	})
+
+	require.NoError(t, client.Get(context.Background(), "/map/test-id/goal", nil))
+	require.Equal(t, []string{primary.URL, primary.URL, mirror.URL}, served)
+
+	require.NoError(t, client.Get(context.Background(), "/map/test-id/goal", nil))
+	require.Equal(t, mirror.URL, served[3], "the primary stays out of rotation during its cooldown")
+
+	primaryFailing.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, client.Get(context.Background(), "/map/test-id/goal", nil))
+	require.NoError(t, client.Get(context.Background(), "/map/test-id/goal", nil))
+	require.Equal(t, []string{primary.URL, primary.URL}, served[4:])
+}
+
+func TestClientFailsOverOnConnectionError(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(goalBody))
+	}))
+	defer mirror.Close()
+
+	client := NewClient(ClientConfig{
+		BaseURLs:          []string{down.URL, mirror.URL},
+		CandidateID:       "test-id",
+		RetryConfig:       pkgretry.Config{MaxAttempts: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1.0},
+		RequestsPerSecond: 100,
+	})
+
+	var goal struct {
+		Goal [][]string `json:"goal"`
+	}
+	require.NoError(t, client.Get(context.Background(), "/map/test-id/goal", &goal))
+	require.Equal(t, "SPACE", goal.Goal[0][0])
+}
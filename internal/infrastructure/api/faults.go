@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is the transport error returned by fault injection
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultConfig describes the failures FaultMiddleware injects. Rates are probabilities between
+// 0 and 1, drawn independently for every attempt.
+type FaultConfig struct {
+	// ErrorRate fails the attempt with ErrInjectedFault without sending it
+	ErrorRate float64
+	// StatusRate answers the attempt with Status without sending it
+	StatusRate float64
+	// Status is the injected status code; zero uses 503
+	Status int
+	// Latency delays every attempt before it is sent
+	Latency time.Duration
+	// Seed makes the injected faults reproducible; zero seeds from the clock
+	Seed int64
+}
+
+// FaultMiddleware injects errors, synthetic responses, and latency, for exercising retries and the
+// circuit breaker without a misbehaving server
+func FaultMiddleware(config FaultConfig) Middleware {
+	if config.Status == 0 {
+		config.Status = http.StatusServiceUnavailable
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	var mu sync.Mutex
+	random := rand.New(rand.NewSource(seed))
+	draw := func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return random.Float64()
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if config.Latency > 0 {
+				timer := time.NewTimer(config.Latency)
+				select {
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, req.Context().Err()
+				case <-timer.C:
+				}
+			}
+
+			if draw() < config.ErrorRate {
+				return nil, fmt.Errorf("%s %s: %w", req.Method, RequestEndpoint(req), ErrInjectedFault)
+			}
+			if draw() < config.StatusRate {
+				body := fmt.Sprintf(`{"error":"injected_fault","message":"injected %d response"}`, config.Status)
+				return &http.Response{
+					Status:        fmt.Sprintf("%d %s", config.Status, http.StatusText(config.Status)),
+					StatusCode:    config.Status,
+					Proto:         "HTTP/1.1",
+					ProtoMajor:    1,
+					ProtoMinor:    1,
+					Header:        http.Header{"Content-Type": []string{"application/json"}},
+					Body:          io.NopCloser(bytes.NewReader([]byte(body))),
+					ContentLength: int64(len(body)),
+					Request:       req,
+				}, nil
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/api"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/goalcache"
+)
+
+// newGoalServer serves a goal map with an ETag and answers matching conditional GETs with 304
+func newGoalServer(t *testing.T, delay time.Duration) (*httptest.Server, *atomic.Int32, *atomic.Int32) {
+	var requests, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(delay)
+		w.Header().Set("ETag", `"goal-v1"`)
+		if r.Header.Get("If-None-Match") == `"goal-v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`{"goal":[["SPACE","POLYANET"]]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests, &notModified
+}
+
+func TestGoalCacheRevalidatesWithETag(t *testing.T) {
+	server, requests, notModified := newGoalServer(t, 0)
+	cache := goalcache.New(t.TempDir())
+	repo := api.NewRepository(newTestClient(server.URL)).WithGoalCache(cache, 0)
+
+	for i := 0; i < 2; i++ {
+		goal, err := repo.GetGoalMap(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, [][]string{{"SPACE", "POLYANET"}}, goal.Goal)
+	}
+	require.EqualValues(t, 2, requests.Load())
+	require.EqualValues(t, 1, notModified.Load(), "the second call revalidates with the cached ETag")
+}
+
+func TestGoalCacheServesFreshEntries(t *testing.T) {
+	server, requests, _ := newGoalServer(t, 0)
+	repo := api.NewRepository(newTestClient(server.URL)).WithGoalCache(goalcache.New(t.TempDir()), time.Hour)
+
+	for i := 0; i < 3; i++ {
+		_, err := repo.GetGoalMap(context.Background())
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 1, requests.Load())
+}
+
+func TestGoalCacheOffline(t *testing.T) {
+	server, _, _ := newGoalServer(t, 0)
+	cache := goalcache.New(t.TempDir())
+
+	offline := api.NewRepository(newTestClient(server.URL)).WithGoalCache(cache, 0).WithOffline(true)
+	_, err := offline.GetGoalMap(context.Background())
+	require.ErrorContains(t, err, "run once without --offline")
+
+	_, err = api.NewRepository(newTestClient(server.URL)).WithGoalCache(cache, 0).GetGoalMap(context.Background())
+	require.NoError(t, err)
+	server.Close()
+
+	goal, err := offline.GetGoalMap(context.Background())
+	require.NoError(t, err, "offline mode never contacts the API")
+	require.Equal(t, "POLYANET", goal.Goal[0][1])
+
+	_, err = api.NewRepository(newTestClient(server.URL)).WithOffline(true).GetGoalMap(context.Background())
+	require.ErrorContains(t, err, "needs the goal cache")
+}
+
+func TestGoalCacheIsKeyedByURL(t *testing.T) {
+	server, _, _ := newGoalServer(t, 0)
+	staging, _, _ := newGoalServer(t, 0)
+	cache := goalcache.New(t.TempDir())
+
+	_, err := api.NewRepository(newTestClient(server.URL)).WithGoalCache(cache, 0).GetGoalMap(context.Background())
+	require.NoError(t, err)
+
+	_, err = api.NewRepository(newTestClient(staging.URL)).WithGoalCache(cache, 0).WithOffline(true).GetGoalMap(context.Background())
+	require.ErrorContains(t, err, "no goal map cached", "another base URL does not share the entry")
+
+	versioned := api.Endpoints{GoalMap: "/v2/map/{candidateId}/goal"}.WithDefaults()
+	_, err = api.NewRepository(newTestClient(server.URL)).WithEndpoints(versioned).WithGoalCache(cache, 0).WithOffline(true).GetGoalMap(context.Background())
+	require.ErrorContains(t, err, "no goal map cached", "another goal map path does not share the entry")
+
+	_, err = api.NewRepository(newTestClient(server.URL)).WithGoalCache(cache, 0).WithOffline(true).GetGoalMap(context.Background())
+	require.NoError(t, err)
+}
+
+func TestGetGoalMapSharesConcurrentRequests(t *testing.T) {
+	server, requests, _ := newGoalServer(t, 100*time.Millisecond)
+	repo := api.NewRepository(newTestClient(server.URL))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			goal, err := repo.GetGoalMap(context.Background())
+			require.NoError(t, err)
+			require.Len(t, goal.Goal, 1)
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, 1, requests.Load())
+}
+
+func TestGetGoalMapOutlivesACancelledCaller(t *testing.T) {
+	server, requests, _ := newGoalServer(t, 100*time.Millisecond)
+	repo := api.NewRepository(newTestClient(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := repo.GetGoalMap(ctx)
+		first <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := repo.GetGoalMap(context.Background())
+		second <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	require.ErrorIs(t, <-first, context.Canceled)
+	require.NoError(t, <-second, "the shared lookup keeps running for the remaining caller")
+	require.EqualValues(t, 1, requests.Load())
+}
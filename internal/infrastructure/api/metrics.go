@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/pkg/metrics"
+)
+
+// clientMetrics holds the metric families the client reports to
+type clientMetrics struct {
+	requests    *metrics.CounterVec
+	retries     *metrics.CounterVec
+	limiterWait *metrics.HistogramVec
+	circuit     *metrics.GaugeVec
+}
+
+func newClientMetrics(registry *metrics.Registry) clientMetrics {
+	return clientMetrics{
+		requests: registry.Counter("megaverse_api_requests_total",
+			"HTTP requests sent to the megaverse API, by endpoint, method, and status.",
+			"endpoint", "method", "status"),
+		retries: registry.Counter("megaverse_api_retries_total",
+			"Retried HTTP attempts against the megaverse API.",
+			"endpoint", "method"),
+		limiterWait: registry.Histogram("megaverse_rate_limiter_wait_seconds",
+			"Time spent waiting on rate limiters before doing work.",
+			nil, "component"),
+		circuit: registry.Gauge("megaverse_api_circuit_state",
+			"State of the API client's circuit breaker: 0 closed, 1 open, 2 half-open."),
+	}
+}
+
+func (m clientMetrics) observeRequest(endpoint, method string, status int) {
+	label := "error"
+	if status > 0 {
+		label = strconv.Itoa(status)
+	}
+	m.requests.With(endpoint, method, label).Inc()
+}
+
+func (m clientMetrics) observeLimiterWait(d time.Duration) {
+	m.limiterWait.With("client").Observe(d.Seconds())
+}
+
+// endpointLabel names endpoint in metrics, traces, and logs. Requests built from an endpoint template
+// are labelled with the unexpanded template, so a path such as /polyanets/{row}/{column} yields one
+// label rather than one per cell; the candidate ID is redacted so labels stay free of secrets.
+func (c *Client) endpointLabel(ctx context.Context, endpoint string) string {
+	if target, ok := targetOf(ctx); ok && target.template != "" {
+		return c.redact(target.template)
+	}
+	return c.redact(endpoint)
+}
+
+// redact replaces every occurrence of the candidate ID in s with a placeholder
+func (c *Client) redact(s string) string {
+	if c.candidateID == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, c.candidateID, "{candidateId}")
+}
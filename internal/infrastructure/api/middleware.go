@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/pkg/metrics"
+)
+
+// Middleware wraps the transport of a Client with cross-cutting behaviour. It sees every attempt,
+// including retries, and must not modify the request it is given; clone it first.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req)
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// chain wraps base in middlewares so that the first middleware sees each request first
+func chain(base http.RoundTripper, middlewares []Middleware) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		base = middlewares[i](base)
+	}
+	return base
+}
+
+type requestInfoKey struct{}
+
+// requestInfo is what the client knows about a request beyond its URL
+type requestInfo struct {
+	endpoint    string // endpoint label, see Client.endpointLabel
+	attempt     int
+	candidateID string
+}
+
+func withRequestInfo(ctx context.Context, info requestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+type requestTargetKey struct{}
+
+// requestTarget is what the repository knows about a request before its templates are expanded.
+// It is set for the whole call, so every attempt of the request sees it.
+type requestTarget struct {
+	template string             // unexpanded path, used as the endpoint label
+	cell     *entities.Position // cell of an object request
+}
+
+func withRequestTarget(ctx context.Context, target requestTarget) context.Context {
+	return context.WithValue(ctx, requestTargetKey{}, target)
+}
+
+func targetOf(ctx context.Context) (requestTarget, bool) {
+	target, ok := ctx.Value(requestTargetKey{}).(requestTarget)
+	return target, ok
+}
+
+// RequestEndpoint returns the endpoint of req as used in metrics labels: its unexpanded template,
+// with the candidate ID redacted. Requests that were not sent by a Client yield their URL path.
+func RequestEndpoint(req *http.Request) string {
+	if info, ok := req.Context().Value(requestInfoKey{}).(requestInfo); ok {
+		return info.endpoint
+	}
+	return req.URL.Path
+}
+
+// RequestAttempt returns which attempt of a Client request req is, starting at 1; zero for
+// requests that were not sent by a Client
+func RequestAttempt(req *http.Request) int {
+	info, _ := req.Context().Value(requestInfoKey{}).(requestInfo)
+	return info.attempt
+}
+
+// redactRequest removes the candidate ID of the Client that sent req from s
+func redactRequest(req *http.Request, s string) string {
+	info, ok := req.Context().Value(requestInfoKey{}).(requestInfo)
+	if !ok || info.candidateID == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, info.candidateID, "{candidateId}")
+}
+
+// LoggingMiddleware logs every round trip at debug level with its endpoint, status, and duration
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			fields := []any{"method", req.Method, "endpoint", RequestEndpoint(req), "attempt", RequestAttempt(req), "duration", time.Since(start)}
+			if err != nil {
+				logger.DebugContext(req.Context(), "round trip failed", append(fields, "error", redactRequest(req, err.Error()))...)
+				return resp, err
+			}
+			logger.DebugContext(req.Context(), "round trip", append(fields, "status", resp.StatusCode)...)
+			return resp, nil
+		})
+	}
+}
+
+// MetricsMiddleware reports the duration of every round trip to registry, by endpoint, method, and
+// status ("error" when no response arrived)
+func MetricsMiddleware(registry *metrics.Registry) Middleware {
+	durations := registry.Histogram("megaverse_http_round_trip_seconds",
+		"Duration of HTTP round trips to the megaverse API, by endpoint, method, and status.",
+		nil, "endpoint", "method", "status")
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			status := "error"
+			if err == nil {
+				status = strconv.Itoa(resp.StatusCode)
+			}
+			durations.With(RequestEndpoint(req), req.Method, status).Observe(time.Since(start).Seconds())
+			return resp, err
+		})
+	}
+}
+
+// HeaderMiddleware sets headers on every request, replacing values the client set itself
+func HeaderMiddleware(headers http.Header) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			for name, values := range headers {
+				req.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/api"
+	"github.com/crossmint/megaverse-challenge/pkg/metrics"
+	pkgretry "github.com/crossmint/megaverse-challenge/pkg/retry"
+)
+
+func newMiddlewareRepository(baseURL string, attempts int, middlewares ...api.Middleware) *api.Repository {
+	return api.NewRepository(api.NewClient(api.ClientConfig{
+		BaseURL:     baseURL,
+		CandidateID: "cand-secret",
+		Timeout:     time.Second,
+		RetryConfig: pkgretry.Config{
+			MaxAttempts:  attempts,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Multiplier:   1.0,
+		},
+		RequestsPerSecond: 100,
+		Middlewares:       middlewares,
+	}))
+}
+
+func TestMiddlewaresRunInOrder(t *testing.T) {
+	var team string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		team = r.Header.Get("X-Team")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var order []string
+	trace := func(name string) api.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return api.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+":"+api.RequestEndpoint(req))
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	repo := newMiddlewareRepository(server.URL, 1,
+		trace("outer"),
+		api.HeaderMiddleware(http.Header{"X-Team": {"platform"}}),
+		trace("inner"))
+	require.NoError(t, repo.CreatePolyanet(context.Background(), entities.Position{Row: 1, Column: 2}))
+
+	require.Equal(t, []string{"outer:/polyanets", "inner:/polyanets"}, order)
+	require.Equal(t, "platform", team)
+}
+
+func TestRecorderRedactsExchanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"goal":[["POLYANET"]]}`))
+	}))
+	defer server.Close()
+
+	var sink bytes.Buffer
+	recorder := api.NewRecorder(&sink)
+	repo := newMiddlewareRepository(server.URL, 1, recorder.Middleware())
+
+	goal, err := repo.GetGoalMap(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "POLYANET", goal.Goal[0][0], "the recorder must hand the body on unchanged")
+
+	require.NoError(t, repo.CreatePolyanet(context.Background(), entities.Position{Row: 1, Column: 2}))
+
+	exchanges := recorder.Exchanges()
+	require.Len(t, exchanges, 2)
+	require.Equal(t, server.URL+"/map/{candidateId}/goal", exchanges[0].URL)
+	require.Equal(t, 200, exchanges[0].Status)
+	require.JSONEq(t, `{"row":1,"column":2,"candidateId":"{candidateId}"}`, exchanges[1].RequestBody)
+	require.Equal(t, 2, strings.Count(sink.String(), "\n"))
+	require.NotContains(t, sink.String(), "cand-secret")
+}
+
+func TestFaultMiddleware(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	repo := newMiddlewareRepository(server.URL, 2, api.FaultMiddleware(api.FaultConfig{ErrorRate: 1, Seed: 1}))
+	err := repo.DeleteObject(context.Background(), "POLYANET", entities.Position{})
+	require.ErrorIs(t, err, api.ErrInjectedFault)
+
+	repo = newMiddlewareRepository(server.URL, 2, api.FaultMiddleware(api.FaultConfig{StatusRate: 1, Status: 502, Seed: 1}))
+	err = repo.DeleteObject(context.Background(), "POLYANET", entities.Position{})
+	require.ErrorIs(t, err, domain.ErrServer)
+
+	require.Zero(t, hits.Load(), "injected faults never reach the server")
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	registry := metrics.NewRegistry()
+	repo := newMiddlewareRepository(server.URL, 1, api.MetricsMiddleware(registry))
+	_, err := repo.GetGoalMap(context.Background())
+	require.Error(t, err, "an empty body is not a goal map")
+
+	var text bytes.Buffer
+	require.NoError(t, registry.WriteText(&text))
+	require.Contains(t, text.String(), `megaverse_http_round_trip_seconds_count{endpoint="/map/{candidateId}/goal",method="GET",status="200"} 1`)
+}
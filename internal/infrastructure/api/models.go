@@ -1,5 +1,7 @@
 package api
 
+import "encoding/json"
+
 // CreatePolyanetRequest represents the request body for creating a Polyanet
 type CreatePolyanetRequest struct {
 	Row        int    `json:"row"`
@@ -30,9 +32,11 @@ type DeleteRequest struct {
 	CandidateID string `json:"candidateId"`
 }
 
-// ErrorResponse represents an error response from the API
+// ErrorResponse represents an error response from the API. Error is either a code string or,
+// on some endpoints, just true alongside a Reason.
 type ErrorResponse struct {
-	Error   string `json:"error,omitempty"`
-	Message string `json:"message,omitempty"`
-	Status  int    `json:"status,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Reason  string          `json:"reason,omitempty"`
+	Status  int             `json:"status,omitempty"`
 }
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/pkg/audit"
+	"github.com/crossmint/megaverse-challenge/pkg/correlation"
+)
+
+// RequestRecord describes one HTTP attempt made by the client
+type RequestRecord struct {
+	Start       time.Time
+	Duration    time.Duration // time on the wire, excluding the rate limiter wait
+	LimiterWait time.Duration
+	Method      string
+	BaseURL     string // base URL that served the attempt
+	Endpoint    string // endpoint template, or the path with the candidate ID replaced by {candidateId}
+	Attempt     int
+	Status      int // 0 when the request failed before a response arrived
+	Error       string
+	RunID       string
+	OperationID string
+}
+
+// RequestObserver is called after every HTTP attempt. It is called from concurrent workers.
+type RequestObserver func(RequestRecord)
+
+// RequestLog collects request records in memory, for reports built at the end of a run
+type RequestLog struct {
+	mu      sync.Mutex
+	records []RequestRecord
+}
+
+// Observe appends record; it satisfies RequestObserver
+func (l *RequestLog) Observe(record RequestRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, record)
+}
+
+// Records returns a copy of the records collected so far, in completion order
+func (l *RequestLog) Records() []RequestRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]RequestRecord(nil), l.records...)
+}
+
+// recordAttempt reports a finished attempt to the observer and, for mutating requests, the audit log.
+// The audit log keeps the expanded endpoint, with the candidate ID redacted, rather than its label.
+func (c *Client) recordAttempt(ctx context.Context, record RequestRecord, endpoint string, payload []byte) {
+	record.RunID = correlation.RunID(ctx)
+	record.OperationID = correlation.OperationID(ctx)
+
+	if c.observer != nil {
+		c.observer(record)
+	}
+
+	if c.auditLog == nil || record.Method == http.MethodGet || record.Method == http.MethodHead {
+		return
+	}
+
+	entry := audit.Entry{
+		Time:        record.Start,
+		RunID:       record.RunID,
+		OperationID: record.OperationID,
+		Method:      record.Method,
+		Endpoint:    c.redact(endpoint),
+		Status:      record.Status,
+		Attempt:     record.Attempt,
+		Error:       record.Error,
+	}
+	if len(payload) > 0 {
+		entry.Body = []byte(c.redact(string(payload)))
+	}
+	if target, ok := targetOf(ctx); ok && target.cell != nil {
+		entry.Cell = &audit.Cell{Row: target.cell.Row, Column: target.cell.Column}
+	}
+	if err := c.auditLog.Append(entry); err != nil {
+		c.logger.WarnContext(ctx, "failed to write audit entry", "method", record.Method, "endpoint", record.Endpoint, "error", err)
+	}
+}
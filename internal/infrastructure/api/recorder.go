@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Exchange is one recorded round trip. Bodies and the URL have the candidate ID redacted.
+type Exchange struct {
+	Time         time.Time   `json:"time"`
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Attempt      int         `json:"attempt,omitempty"`
+	RequestBody  string      `json:"request_body,omitempty"`
+	Status       int         `json:"status,omitempty"`
+	Header       http.Header `json:"header,omitempty"`
+	ResponseBody string      `json:"response_body,omitempty"`
+	Error        string      `json:"error,omitempty"`
+	DurationMS   float64     `json:"duration_ms"`
+}
+
+// Recorder keeps every round trip that passes through its middleware, and optionally writes each
+// one as a JSON line. It is safe for concurrent use.
+type Recorder struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	encoder   *json.Encoder
+}
+
+// NewRecorder returns a recorder that also writes exchanges to sink when it is not nil
+func NewRecorder(sink io.Writer) *Recorder {
+	r := &Recorder{}
+	if sink != nil {
+		r.encoder = json.NewEncoder(sink)
+	}
+	return r
+}
+
+// Exchanges returns a copy of the recorded round trips in the order they finished
+func (r *Recorder) Exchanges() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Exchange(nil), r.exchanges...)
+}
+
+// Middleware records every round trip. Request and response bodies are read in full and replaced
+// with in-memory copies, so they are still sent and returned unchanged.
+func (r *Recorder) Middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			exchange := Exchange{
+				Time:    time.Now(),
+				Method:  req.Method,
+				URL:     redactRequest(req, req.URL.String()),
+				Attempt: RequestAttempt(req),
+			}
+
+			if req.Body != nil && req.GetBody != nil {
+				body, err := req.GetBody()
+				if err == nil {
+					data, _ := io.ReadAll(body)
+					body.Close()
+					exchange.RequestBody = redactRequest(req, string(data))
+				}
+			}
+
+			resp, err := next.RoundTrip(req)
+			exchange.DurationMS = float64(time.Since(exchange.Time).Microseconds()) / 1000
+			if err != nil {
+				exchange.Error = redactRequest(req, err.Error())
+			} else {
+				data, readErr := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				resp.Body = io.NopCloser(bytes.NewReader(data))
+				exchange.Status = resp.StatusCode
+				exchange.Header = resp.Header.Clone()
+				exchange.ResponseBody = redactRequest(req, string(data))
+				if readErr != nil {
+					exchange.Error = fmt.Sprintf("reading response body: %v", readErr)
+				}
+			}
+
+			r.add(exchange)
+			return resp, err
+		})
+	}
+}
+
+func (r *Recorder) add(exchange Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, exchange)
+	if r.encoder != nil {
+		// A failing sink must not fail the request being recorded.
+		_ = r.encoder.Encode(exchange)
+	}
+}
@@ -4,97 +4,246 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 
 	"github.com/crossmint/megaverse-challenge/internal/domain"
 	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/goalcache"
 )
 
 // Repository implements the MegaverseRepository interface using the HTTP API
 type Repository struct {
-	client *Client
+	client    *Client
+	endpoints Endpoints
+
+	goals     singleflight.Group
+	goalCache *goalcache.Cache
+	goalTTL   time.Duration
+	offline   bool
 }
 
 // NewRepository creates a new API repository
 func NewRepository(client *Client) *Repository {
 	return &Repository{
-		client: client,
+		client:    client,
+		endpoints: DefaultEndpoints(),
 	}
 }
 
+// WithEndpoints replaces the default endpoint templates; fields left empty keep their defaults
+func (r *Repository) WithEndpoints(endpoints Endpoints) *Repository {
+	r.endpoints = endpoints.WithDefaults()
+	return r
+}
+
+// WithGoalCache keeps goal maps in cache. A cached goal map younger than ttl is used without
+// contacting the API; older ones are revalidated with a conditional GET.
+func (r *Repository) WithGoalCache(cache *goalcache.Cache, ttl time.Duration) *Repository {
+	r.goalCache = cache
+	r.goalTTL = ttl
+	return r
+}
+
+// WithOffline makes GetGoalMap read the goal cache only, however old its entry is
+func (r *Repository) WithOffline(offline bool) *Repository {
+	r.offline = offline
+	return r
+}
+
 // CreatePolyanet creates a new Polyanet at the specified position
 func (r *Repository) CreatePolyanet(ctx context.Context, position entities.Position) error {
-	req := CreatePolyanetRequest{
-		Row:         position.Row,
-		Column:      position.Column,
-		CandidateID: r.client.GetCandidateID(),
-	}
-
-	return r.client.Post(ctx, "/polyanets", req)
+	return r.create(ctx, &entities.Polyanet{Position: position}, nil)
 }
 
 // CreateSoloon creates a new Soloon with the specified color at the given position
 func (r *Repository) CreateSoloon(ctx context.Context, position entities.Position, color entities.SoloonColor) error {
-	req := CreateSoloonRequest{
-		Row:         position.Row,
-		Column:      position.Column,
-		Color:       string(color),
-		CandidateID: r.client.GetCandidateID(),
-	}
-
-	return r.client.Post(ctx, "/soloons", req)
+	return r.create(ctx, &entities.Soloon{Position: position, Color: color}, map[string]string{"color": string(color)})
 }
 
 // CreateCometh creates a new Cometh with the specified direction at the given position
 func (r *Repository) CreateCometh(ctx context.Context, position entities.Position, direction entities.ComethDirection) error {
-	req := CreateComethRequest{
-		Row:         position.Row,
-		Column:      position.Column,
-		Direction:   string(direction),
-		CandidateID: r.client.GetCandidateID(),
+	return r.create(ctx, &entities.Cometh{Position: position, Direction: direction}, map[string]string{"direction": string(direction)})
+}
+
+// create sends the create request of obj's type, expanded with the position and extra placeholders
+func (r *Repository) create(ctx context.Context, obj entities.AstralObject, extra map[string]string) error {
+	endpoints, ok := r.endpoints.Objects[obj.GetType()]
+	if !ok {
+		return fmt.Errorf("unknown object type: %s", obj.GetType())
+	}
+
+	vars := r.objectVars(obj.GetType(), obj.GetPosition())
+	vars["color"], vars["direction"] = "", ""
+	for name, value := range extra {
+		vars[name] = value
+	}
+
+	path, body, err := r.request(endpoints.Create, vars)
+	if err != nil {
+		return err
 	}
+	pos := obj.GetPosition()
+	ctx = withRequestTarget(ctx, requestTarget{template: endpoints.Create.Path, cell: &pos})
+	return r.client.SendIdempotent(ctx, endpoints.Create.Method, path, body, r.holds(obj))
+}
 
-	return r.client.Post(ctx, "/comeths", req)
+func (r *Repository) objectVars(objectType string, position entities.Position) map[string]string {
+	return objectVars(r.client.GetCandidateID(), strings.ToLower(objectType), position.Row, position.Column)
+}
+
+// request expands the path and body of endpoint
+func (r *Repository) request(endpoint Endpoint, vars map[string]string) (string, interface{}, error) {
+	path, err := expand(endpoint.Path, vars, false)
+	if err != nil {
+		return "", nil, err
+	}
+	body, err := endpoint.body(vars)
+	if err != nil {
+		return "", nil, err
+	}
+	if body == nil {
+		// A nil json.RawMessage in an interface would still be sent as "null".
+		return path, nil, nil
+	}
+	return path, body, nil
+}
+
+// holds returns a check that the live map already contains want, with the same attributes, which
+// makes creating it again unnecessary
+func (r *Repository) holds(want entities.AstralObject) AppliedFunc {
+	return func(ctx context.Context) (bool, error) {
+		current, err := r.GetCurrentMap(ctx)
+		if err != nil {
+			return false, err
+		}
+		pos := want.GetPosition()
+		got, err := current.GetObject(pos.Row, pos.Column)
+		if err != nil {
+			return false, err
+		}
+		return entities.SameObject(got, want), nil
+	}
 }
 
 // DeleteObject removes an astral object at the specified position
 func (r *Repository) DeleteObject(ctx context.Context, objectType string, position entities.Position) error {
-	req := DeleteRequest{
-		Row:         position.Row,
-		Column:      position.Column,
-		CandidateID: r.client.GetCandidateID(),
-	}
-
-	var endpoint string
-	switch objectType {
-	case "POLYANET":
-		endpoint = "/polyanets"
-	case "SOLOON":
-		endpoint = "/soloons"
-	case "COMETH":
-		endpoint = "/comeths"
-	default:
+	endpoints, ok := r.endpoints.Objects[objectType]
+	if !ok {
 		return fmt.Errorf("unknown object type: %s", objectType)
 	}
 
-	return r.client.Delete(ctx, endpoint, req)
+	path, body, err := r.request(endpoints.Delete, r.objectVars(objectType, position))
+	if err != nil {
+		return err
+	}
+	ctx = withRequestTarget(ctx, requestTarget{template: endpoints.Delete.Path, cell: &position})
+	return r.client.Send(ctx, endpoints.Delete.Method, path, body)
 }
 
-// GetGoalMap retrieves the goal map for the current challenge phase
+// GetGoalMap retrieves the goal map for the current challenge phase. Concurrent calls share one
+// lookup. It runs detached from the caller that started it, bounded by the client timeout, so one
+// cancelled caller does not fail the others; each caller still stops waiting when its ctx is done.
 func (r *Repository) GetGoalMap(ctx context.Context) (*domain.GoalMap, error) {
-	endpoint := fmt.Sprintf("/map/%s/goal", r.client.GetCandidateID())
+	shared := r.goals.DoChan("goal", func() (interface{}, error) {
+		return r.fetchGoalMap(context.WithoutCancel(ctx))
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-shared:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*domain.GoalMap), nil
+	}
+}
+
+// fetchGoalMap reads the goal map from the cache when it is fresh, or when offline, and from the
+// API otherwise, refreshing the cache
+func (r *Repository) fetchGoalMap(ctx context.Context) (*domain.GoalMap, error) {
+	ctx = withRequestTarget(ctx, requestTarget{template: r.endpoints.GoalMap})
+	endpoint, err := r.mapPath(r.endpoints.GoalMap)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.goalCache == nil {
+		if r.offline {
+			return nil, fmt.Errorf("offline mode needs the goal cache; set cache.dir")
+		}
+		var goalMap domain.GoalMap
+		if err := r.client.Get(ctx, endpoint, &goalMap); err != nil {
+			return nil, fmt.Errorf("failed to get goal map: %w", err)
+		}
+		return &goalMap, nil
+	}
+
+	logger := r.client.logger
+	key := r.goalCacheKey(endpoint)
+	now := time.Now()
+
+	cached, err := r.goalCache.Load(key)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.WarnContext(ctx, "ignoring unreadable goal cache", "error", err)
+	}
+
+	if r.offline {
+		if cached == nil {
+			return nil, fmt.Errorf("no goal map cached for this candidate and API in %s; run once without --offline to fill it", r.goalCache.Dir())
+		}
+		logger.InfoContext(ctx, "using cached goal map (offline)", "age", cached.Age(now).Round(time.Second))
+		return &cached.Goal, nil
+	}
+	if cached != nil && cached.Fresh(r.goalTTL, now) {
+		logger.DebugContext(ctx, "using cached goal map", "age", cached.Age(now).Round(time.Second))
+		return &cached.Goal, nil
+	}
+
+	var validators Validators
+	if cached != nil {
+		validators = Validators{ETag: cached.ETag, LastModified: cached.LastModified}
+	}
 
 	var goalMap domain.GoalMap
-	if err := r.client.Get(ctx, endpoint, &goalMap); err != nil {
+	validators, notModified, err := r.client.GetIfChanged(ctx, endpoint, validators, &goalMap)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get goal map: %w", err)
 	}
+	if notModified {
+		logger.DebugContext(ctx, "cached goal map still current", "age", cached.Age(now).Round(time.Second))
+		goalMap = cached.Goal
+	}
 
+	entry := goalcache.Entry{Goal: goalMap, ETag: validators.ETag, LastModified: validators.LastModified, FetchedAt: now}
+	if err := r.goalCache.Save(key, entry); err != nil {
+		logger.WarnContext(ctx, "failed to update goal cache", "error", err)
+	}
 	return &goalMap, nil
 }
 
+// goalCacheKey identifies the goal map fetched from endpoint in the goal cache: the candidate ID
+// and the URL, so a staging or versioned API never shares entries with another server
+func (r *Repository) goalCacheKey(endpoint string) string {
+	return r.client.GetCandidateID() + " " + r.client.BaseURL() + endpoint
+}
+
+// mapPath expands a map endpoint template
+func (r *Repository) mapPath(template string) (string, error) {
+	return expand(template, map[string]string{"candidateId": r.client.GetCandidateID()}, false)
+}
+
 // GetCurrentMap retrieves the current state of the megaverse
 func (r *Repository) GetCurrentMap(ctx context.Context) (*entities.Megaverse, error) {
 	// callers should treat a 404 as “not supported”.
-	endpoint := fmt.Sprintf("/map/%s", r.client.GetCandidateID())
+	ctx = withRequestTarget(ctx, requestTarget{template: r.endpoints.CurrentMap})
+	endpoint, err := r.mapPath(r.endpoints.CurrentMap)
+	if err != nil {
+		return nil, err
+	}
 
 	type apiCell struct {
 		Type      *int   `json:"type,omitempty"`
@@ -110,9 +259,8 @@ func (r *Repository) GetCurrentMap(ctx context.Context) (*entities.Megaverse, er
 
 	if err := r.client.Get(ctx, endpoint, &response); err != nil {
 		// If the endpoint doesn't exist, return nil
-		var apiErr *domain.APIError
-		if errors.As(err, &apiErr) && apiErr.StatusCode == 404 {
-			return nil, fmt.Errorf("current map endpoint not available")
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("current map endpoint not available: %w", err)
 		}
 		return nil, fmt.Errorf("failed to get current map: %w", err)
 	}
@@ -157,7 +305,20 @@ func (r *Repository) GetCurrentMap(ctx context.Context) (*entities.Megaverse, er
 
 // IsHealthy checks if the API service is healthy and reachable
 func (r *Repository) IsHealthy(ctx context.Context) error {
-	// Try to fetch the goal map as a health check
-	_, err := r.GetGoalMap(ctx)
+	ctx = withRequestTarget(ctx, requestTarget{template: r.endpoints.GoalMap})
+	endpoint, err := r.mapPath(r.endpoints.GoalMap)
+	if err != nil {
+		return err
+	}
+
+	// A cached goal map says nothing about the API, so ask it; the cache's validators keep the
+	// answer small.
+	var validators Validators
+	if r.goalCache != nil {
+		if cached, err := r.goalCache.Load(r.goalCacheKey(endpoint)); err == nil {
+			validators = Validators{ETag: cached.ETag, LastModified: cached.LastModified}
+		}
+	}
+	_, _, err = r.client.GetIfChanged(ctx, endpoint, validators, nil)
 	return err
 }
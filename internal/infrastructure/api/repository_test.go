@@ -1,15 +1,25 @@
 package api_test
 
 import (
+	"bytes"
 	"context"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
 	"github.com/crossmint/megaverse-challenge/internal/infrastructure/api"
+	"github.com/crossmint/megaverse-challenge/pkg/audit"
+	"github.com/crossmint/megaverse-challenge/pkg/breaker"
+	"github.com/crossmint/megaverse-challenge/pkg/correlation"
 	pkgretry "github.com/crossmint/megaverse-challenge/pkg/retry"
 )
 
@@ -77,3 +87,242 @@ func TestGetCurrentMapHandlesEmptyContent(t *testing.T) {
 	require.Equal(t, 0, megaverse.Height)
 	require.Equal(t, 0, megaverse.Width)
 }
+
+func TestRequestsCarryRequestID(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "run-1/op-1", r.Header.Get("X-Request-ID"))
+		w.WriteHeader(http.StatusOK)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+
+	repo := api.NewRepository(newTestClient(server.URL))
+
+	ctx := correlation.WithOperationID(correlation.WithRunID(context.Background(), "run-1"), "op-1")
+	require.NoError(t, repo.CreatePolyanet(ctx, entities.Position{Row: 1, Column: 2}))
+}
+
+func TestMutatingRequestsAreAudited(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"map":{"content":[]}}`))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := audit.Open(path)
+	require.NoError(t, err)
+
+	repo := api.NewRepository(api.NewClient(api.ClientConfig{
+		BaseURL:           server.URL,
+		CandidateID:       "secret-candidate",
+		Timeout:           time.Second,
+		RetryConfig:       pkgretry.Config{MaxAttempts: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1},
+		RequestsPerSecond: 100,
+		AuditLog:          auditLog,
+	}))
+
+	ctx := correlation.WithRunID(context.Background(), "run-1")
+	require.NoError(t, repo.CreatePolyanet(ctx, entities.Position{Row: 1, Column: 2}))
+	require.NoError(t, repo.DeleteObject(ctx, "POLYANET", entities.Position{Row: 1, Column: 2}))
+	_, err = repo.GetCurrentMap(ctx)
+	require.NoError(t, err)
+	require.NoError(t, auditLog.Close())
+
+	verified, err := audit.Verify(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, verified, "GET requests are not audited")
+
+	entries, err := audit.Read(path)
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPost, entries[0].Method)
+	require.Equal(t, "run-1", entries[0].RunID)
+	require.Equal(t, 200, entries[0].Status)
+	require.Contains(t, string(entries[0].Body), `"candidateId":"{candidateId}"`)
+	require.Equal(t, &audit.Cell{Row: 1, Column: 2}, entries[0].Cell)
+	require.Equal(t, &audit.Cell{Row: 1, Column: 2}, entries[1].Cell)
+	require.NotContains(t, string(entries[1].Body), "secret-candidate")
+}
+
+func TestTransportErrorsAreRedacted(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	var records []api.RequestRecord
+	var logs bytes.Buffer
+	repo := api.NewRepository(api.NewClient(api.ClientConfig{
+		BaseURL:           server.URL,
+		CandidateID:       "secret-candidate",
+		Timeout:           time.Second,
+		RetryConfig:       pkgretry.Config{MaxAttempts: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1},
+		RequestsPerSecond: 100,
+		Logger:            slog.New(slog.NewTextHandler(&logs, nil)),
+		Observer:          func(record api.RequestRecord) { records = append(records, record) },
+	}))
+
+	_, err := repo.GetCurrentMap(context.Background())
+	require.Error(t, err)
+
+	require.Len(t, records, 1)
+	require.Contains(t, records[0].Error, "/map/{candidateId}")
+	require.NotContains(t, records[0].Error, "secret-candidate")
+
+	require.Contains(t, logs.String(), "request failed")
+	require.NotContains(t, logs.String(), "secret-candidate")
+}
+
+func TestRateLimitHeadersPauseTheClient(t *testing.T) {
+	var attempts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts = append(attempts, time.Now())
+		if len(attempts) == 1 {
+			w.Header().Set("X-RateLimit-Reset-After", "0.2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The retry delay is capped at a millisecond, so only the limiter pause can hold the retry back.
+	client := api.NewClient(api.ClientConfig{
+		BaseURL:     server.URL,
+		CandidateID: "test-id",
+		Timeout:     time.Second,
+		RetryConfig: pkgretry.Config{
+			MaxAttempts:  2,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Multiplier:   1.0,
+		},
+		RequestsPerSecond: 100,
+	})
+	repo := api.NewRepository(client)
+
+	require.NoError(t, repo.CreatePolyanet(context.Background(), entities.Position{Row: 1, Column: 1}))
+	require.Len(t, attempts, 2)
+	require.GreaterOrEqual(t, attempts[1].Sub(attempts[0]), 200*time.Millisecond)
+}
+
+// newIdempotencyServer serves a 2x2 map whose cell 1,1 holds cell once a create has been applied.
+// post decides how each create is answered.
+func newIdempotencyServer(t *testing.T, cell string, post func(w http.ResponseWriter, n int) bool) (*httptest.Server, *int) {
+	var mu sync.Mutex
+	posts := 0
+	applied := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodGet {
+			content := "null"
+			if applied {
+				content = cell
+			}
+			_, _ = w.Write([]byte(`{"map":{"content":[[null,null],[null,` + content + `]]}}`))
+			return
+		}
+		posts++
+		applied = post(w, posts)
+	}))
+	t.Cleanup(server.Close)
+	return server, &posts
+}
+
+func newRetryingClient(baseURL string) *api.Repository {
+	return api.NewRepository(api.NewClient(api.ClientConfig{
+		BaseURL:     baseURL,
+		CandidateID: "test-id",
+		Timeout:     time.Second,
+		RetryConfig: pkgretry.Config{
+			MaxAttempts:  3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Multiplier:   1.0,
+		},
+		RequestsPerSecond: 100,
+	}))
+}
+
+func TestCreateVerifiesAfterAmbiguousFailure(t *testing.T) {
+	// The first create is applied, but the connection drops before the response is sent.
+	server, posts := newIdempotencyServer(t, `{"type":0}`, func(w http.ResponseWriter, n int) bool {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+		return true
+	})
+
+	err := newRetryingClient(server.URL).CreatePolyanet(context.Background(), entities.Position{Row: 1, Column: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, *posts, "a create confirmed on the map must not be sent again")
+}
+
+func TestCreateRetriesWhenAmbiguousFailureWasNotApplied(t *testing.T) {
+	server, posts := newIdempotencyServer(t, `{"type":0}`, func(w http.ResponseWriter, n int) bool {
+		if n == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return false
+		}
+		return true
+	})
+
+	err := newRetryingClient(server.URL).CreatePolyanet(context.Background(), entities.Position{Row: 1, Column: 1})
+	require.NoError(t, err)
+	require.Equal(t, 2, *posts)
+}
+
+func TestCreateConflictWithSameObjectSucceeds(t *testing.T) {
+	server, _ := newIdempotencyServer(t, `{"type":1,"color":"red"}`, func(w http.ResponseWriter, n int) bool {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":"occupied"}`))
+		return true
+	})
+	repo := newRetryingClient(server.URL)
+
+	require.NoError(t, repo.CreateSoloon(context.Background(), entities.Position{Row: 1, Column: 1}, entities.RedSoloon))
+
+	err := repo.CreateSoloon(context.Background(), entities.Position{Row: 1, Column: 1}, entities.BlueSoloon)
+	require.ErrorIs(t, err, domain.ErrConflict)
+}
+
+func TestCircuitBreakerFailsFastWhileOpen(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var transitions []breaker.Transition
+	client := api.NewClient(api.ClientConfig{
+		BaseURL:     server.URL,
+		CandidateID: "test-id",
+		Timeout:     time.Second,
+		RetryConfig: pkgretry.Config{
+			MaxAttempts:  5,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Multiplier:   1.0,
+		},
+		RequestsPerSecond: 100,
+		CircuitBreaker:    breaker.Config{FailureRatio: 0.5, Window: time.Minute, MinRequests: 2, Cooldown: time.Hour},
+		OnCircuitChange:   func(t breaker.Transition) { transitions = append(transitions, t) },
+	})
+	repo := api.NewRepository(client)
+
+	err := repo.DeleteObject(context.Background(), "POLYANET", entities.Position{Row: 0, Column: 0})
+	require.ErrorIs(t, err, breaker.ErrOpen)
+	require.EqualValues(t, 2, hits.Load(), "attempts after the breaker opened must not reach the server")
+
+	err = repo.DeleteObject(context.Background(), "POLYANET", entities.Position{Row: 0, Column: 0})
+	require.ErrorIs(t, err, breaker.ErrOpen)
+	require.EqualValues(t, 2, hits.Load())
+
+	require.Len(t, transitions, 1)
+	require.Equal(t, breaker.Open, transitions[0].To)
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// TransportConfig tunes connection handling of the client's HTTP transport. Zero fields keep the
+// defaults of http.DefaultTransport.
+type TransportConfig struct {
+	// MaxIdleConnsPerHost is how many idle connections to the API are kept for reuse
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes idle connections after this long
+	IdleConnTimeout time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake of new connections
+	TLSHandshakeTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period of new connections; negative disables keep-alive probes
+	KeepAlive time.Duration
+	// DisableHTTP2 keeps connections on HTTP/1.1
+	DisableHTTP2 bool
+	// Proxy routes every request through this proxy; nil honours HTTP_PROXY, HTTPS_PROXY, and NO_PROXY
+	Proxy *url.URL
+	// TLS replaces the default TLS settings, e.g. to trust an intercepting proxy's CA
+	TLS *tls.Config
+}
+
+// TLSFiles names the PEM files that customise how the client verifies the API and identifies itself
+type TLSFiles struct {
+	// CAFile holds certificates trusted in addition to the system roots
+	CAFile string
+	// CertFile and KeyFile hold a client certificate presented to the server; both or neither
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify accepts any server certificate. Only for debugging: anyone on the network
+	// path can then read and change API traffic.
+	InsecureSkipVerify bool
+}
+
+// Load builds the TLS settings described by f, or returns nil when f asks for the defaults
+func (f TLSFiles) Load() (*tls.Config, error) {
+	if f == (TLSFiles{}) {
+		return nil, nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: f.InsecureSkipVerify}
+
+	if f.CAFile != "" {
+		pem, err := os.ReadFile(f.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA file %s holds no PEM certificates", f.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if f.CertFile != "" || f.KeyFile != "" {
+		if f.CertFile == "" || f.KeyFile == "" {
+			return nil, fmt.Errorf("a client certificate needs both a certificate and a key file")
+		}
+		cert, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// newTransport builds an HTTP transport from config
+func newTransport(config TransportConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	keepAlive := config.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = 30 * time.Second
+	}
+	transport.DialContext = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: keepAlive}).DialContext
+
+	if config.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+		transport.MaxIdleConns = max(transport.MaxIdleConns, config.MaxIdleConnsPerHost)
+	}
+	if config.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = config.IdleConnTimeout
+	}
+	if config.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+	}
+	if config.Proxy != nil {
+		transport.Proxy = http.ProxyURL(config.Proxy)
+	}
+	if config.TLS != nil {
+		transport.TLSClientConfig = config.TLS.Clone()
+	}
+	if config.DisableHTTP2 {
+		// A non-nil, empty TLSNextProto map is how net/http is told not to negotiate HTTP/2.
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return transport
+}
+
+// withTimeout bounds ctx by timeout when it is positive
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// cancelOnClose releases a request's context once its response body is closed, so the deadline
+// keeps covering the body while callers read it
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
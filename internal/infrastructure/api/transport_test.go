@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pkgretry "github.com/crossmint/megaverse-challenge/pkg/retry"
+)
+
+func TestNewTransport(t *testing.T) {
+	transport := newTransport(TransportConfig{MaxIdleConnsPerHost: 32, IdleConnTimeout: time.Minute, TLSHandshakeTimeout: 3 * time.Second, DisableHTTP2: true})
+	require.Equal(t, 32, transport.MaxIdleConnsPerHost)
+	require.GreaterOrEqual(t, transport.MaxIdleConns, 32)
+	require.Equal(t, time.Minute, transport.IdleConnTimeout)
+	require.Equal(t, 3*time.Second, transport.TLSHandshakeTimeout)
+	require.False(t, transport.ForceAttemptHTTP2)
+	require.NotNil(t, transport.TLSNextProto)
+
+	defaults := newTransport(TransportConfig{})
+	require.True(t, defaults.ForceAttemptHTTP2)
+	require.Equal(t, http.DefaultTransport.(*http.Transport).IdleConnTimeout, defaults.IdleConnTimeout)
+}
+
+// slowServer delays the first n requests by delay, or until the client gives up on them
+func slowServer(t *testing.T, n int32, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= n {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		_, _ = w.Write([]byte(goalBody))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func newTimeoutClient(baseURL string, timeout, attemptTimeout time.Duration) *Client {
+	return NewClient(ClientConfig{
+		BaseURL:        baseURL,
+		CandidateID:    "test-id",
+		Timeout:        timeout,
+		AttemptTimeout: attemptTimeout,
+		RetryConfig: pkgretry.Config{
+			MaxAttempts:  5,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Multiplier:   1.0,
+		},
+		RequestsPerSecond: 100,
+	})
+}
+
+func TestAttemptTimeoutRetriesSlowAttempt(t *testing.T) {
+	server, hits := slowServer(t, 1, 5*time.Second)
+	client := newTimeoutClient(server.URL, 5*time.Second, 100*time.Millisecond)
+
+	var goal struct {
+		Goal [][]string `json:"goal"`
+	}
+	start := time.Now()
+	require.NoError(t, client.Get(context.Background(), "/map/test-id/goal", &goal))
+	require.Less(t, time.Since(start), 2*time.Second)
+	require.EqualValues(t, 2, hits.Load())
+	require.Equal(t, "SPACE", goal.Goal[0][0], "the body must stay readable after doRequest returns")
+}
+
+func TestTimeoutBoundsTheWholeCall(t *testing.T) {
+	server, _ := slowServer(t, 100, 5*time.Second)
+	client := newTimeoutClient(server.URL, 300*time.Millisecond, 0)
+
+	start := time.Now()
+	err := client.Get(context.Background(), "/map/test-id/goal", nil)
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 2*time.Second)
+}
+
+const goalBody = `{"goal":[["SPACE"]]}`
+
+func newTransportClient(baseURL string, transport TransportConfig) *Client {
+	return NewClient(ClientConfig{
+		BaseURL:           baseURL,
+		CandidateID:       "test-id",
+		Timeout:           5 * time.Second,
+		Transport:         transport,
+		RetryConfig:       pkgretry.Config{MaxAttempts: 1},
+		RequestsPerSecond: 100,
+	})
+}
+
+// writePEM writes one PEM block of the given type to a file in dir and returns its path
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func TestTLSFilesTrustCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(goalBody))
+	}))
+	defer server.Close()
+
+	err := newTransportClient(server.URL, TransportConfig{}).Get(context.Background(), "/map/test-id/goal", nil)
+	require.Error(t, err, "an unknown CA must be rejected by default")
+
+	caFile := writePEM(t, t.TempDir(), "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	tlsConfig, err := TLSFiles{CAFile: caFile}.Load()
+	require.NoError(t, err)
+	require.NoError(t, newTransportClient(server.URL, TransportConfig{TLS: tlsConfig}).Get(context.Background(), "/map/test-id/goal", nil))
+}
+
+func TestTLSFilesInsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(goalBody))
+	}))
+	defer server.Close()
+
+	tlsConfig, err := TLSFiles{InsecureSkipVerify: true}.Load()
+	require.NoError(t, err)
+	require.NoError(t, newTransportClient(server.URL, TransportConfig{TLS: tlsConfig}).Get(context.Background(), "/map/test-id/goal", nil))
+}
+
+func TestTLSFilesClientCertificate(t *testing.T) {
+	var peer atomic.Value
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer.Store(r.TLS.PeerCertificates[0].Subject.CommonName)
+		_, _ = w.Write([]byte(goalBody))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "megaverse-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	files := TLSFiles{
+		CAFile:   writePEM(t, dir, "ca.pem", "CERTIFICATE", server.Certificate().Raw),
+		CertFile: writePEM(t, dir, "client.pem", "CERTIFICATE", certDER),
+		KeyFile:  writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER),
+	}
+	tlsConfig, err := files.Load()
+	require.NoError(t, err)
+	require.NoError(t, newTransportClient(server.URL, TransportConfig{TLS: tlsConfig}).Get(context.Background(), "/map/test-id/goal", nil))
+	require.Equal(t, "megaverse-test", peer.Load())
+}
+
+func TestTLSFilesErrors(t *testing.T) {
+	tlsConfig, err := TLSFiles{}.Load()
+	require.NoError(t, err)
+	require.Nil(t, tlsConfig, "no files keep the default TLS settings")
+
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+	_, err = TLSFiles{CAFile: notPEM}.Load()
+	require.ErrorContains(t, err, "no PEM certificates")
+	_, err = TLSFiles{CAFile: filepath.Join(dir, "missing.pem")}.Load()
+	require.ErrorContains(t, err, "failed to read CA file")
+	_, err = TLSFiles{CertFile: notPEM}.Load()
+	require.ErrorContains(t, err, "both a certificate and a key file")
+}
+
+func TestTransportProxy(t *testing.T) {
+	var requested atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested.Store(r.URL.String())
+		_, _ = w.Write([]byte(goalBody))
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	client := newTransportClient("http://megaverse.invalid/api", TransportConfig{Proxy: proxyURL})
+	require.NoError(t, client.Get(context.Background(), "/map/test-id/goal", nil))
+	require.Equal(t, "http://megaverse.invalid/api/map/test-id/goal", requested.Load())
+}
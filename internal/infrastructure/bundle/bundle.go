@@ -0,0 +1,185 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"time"
+)
+
+// FormatVersion is the layout version recorded in every manifest
+const FormatVersion = 1
+
+// File names that exist only inside bundles; the other files keep their run directory names
+const (
+	ManifestFile = "manifest.json" // written last, lists the files before it
+	AuditFile    = "audit.jsonl"   // the audit entries recorded for the bundled run
+)
+
+// Manifest describes the contents of a bundle
+type Manifest struct {
+	Version   int       `json:"version"`
+	RunID     string    `json:"run_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []File    `json:"files"`
+	// Missing lists artifacts the run did not record, such as snapshots of runs made before they existed
+	Missing []string `json:"missing,omitempty"`
+}
+
+// File is one archived artifact
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Writer builds a gzipped tar archive with every file under a directory named after the run
+type Writer struct {
+	gz       *gzip.Writer
+	tar      *tar.Writer
+	manifest Manifest
+}
+
+// NewWriter starts a bundle for runID on w
+func NewWriter(w io.Writer, runID string) *Writer {
+	gz := gzip.NewWriter(w)
+	return &Writer{
+		gz:       gz,
+		tar:      tar.NewWriter(gz),
+		manifest: Manifest{Version: FormatVersion, RunID: runID, CreatedAt: time.Now().UTC()},
+	}
+}
+
+// Add archives data under name and records its checksum in the manifest
+func (w *Writer) Add(name string, data []byte) error {
+	if err := w.write(name, data); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	w.manifest.Files = append(w.manifest.Files, File{Name: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+	return nil
+}
+
+// Missing records that the artifact name could not be included
+func (w *Writer) Missing(name string) {
+	w.manifest.Missing = append(w.manifest.Missing, name)
+}
+
+// Close writes the manifest and flushes the archive; it does not close the underlying writer
+func (w *Writer) Close() error {
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := w.write(ManifestFile, append(data, '\n')); err != nil {
+		return err
+	}
+	if err := w.tar.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return w.gz.Close()
+}
+
+func (w *Writer) write(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    path.Join(w.manifest.RunID, name),
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: w.manifest.CreatedAt,
+	}
+	if err := w.tar.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", name, err)
+	}
+	if _, err := w.tar.Write(data); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", name, err)
+	}
+	return nil
+}
+
+// Bundle is an archive read back into memory
+type Bundle struct {
+	Manifest Manifest
+	Files    map[string][]byte
+}
+
+// Names returns the archived file names in manifest order
+func (b *Bundle) Names() []string {
+	names := make([]string, 0, len(b.Manifest.Files))
+	for _, file := range b.Manifest.Files {
+		names = append(names, file.Name)
+	}
+	return names
+}
+
+// Read loads a bundle and checks every file against the manifest, so a truncated or edited
+// archive is reported rather than summarised
+func Read(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a bundle: %w", err)
+	}
+	defer gz.Close()
+
+	b := &Bundle{Files: make(map[string][]byte)}
+	var manifest []byte
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		name := path.Base(header.Name)
+		if name == ManifestFile {
+			manifest = data
+			continue
+		}
+		b.Files[name] = data
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("archive has no %s", ManifestFile)
+	}
+	if err := json.Unmarshal(manifest, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if b.Manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("bundle format version %d is newer than the supported version %d", b.Manifest.Version, FormatVersion)
+	}
+
+	var problems []string
+	for _, file := range b.Manifest.Files {
+		data, ok := b.Files[file.Name]
+		if !ok {
+			problems = append(problems, file.Name+" is missing")
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != file.SHA256 {
+			problems = append(problems, file.Name+" does not match its checksum")
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("bundle is damaged: %v", problems)
+	}
+
+	return b, nil
+}
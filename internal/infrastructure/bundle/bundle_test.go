@@ -0,0 +1,52 @@
+package bundle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/runstore"
+)
+
+func writeTestBundle(t *testing.T) []byte {
+	t.Helper()
+
+	var archive bytes.Buffer
+	w := NewWriter(&archive, "run-1")
+	require.NoError(t, w.Add(runstore.ReportFile, []byte(`{"name":"phase1","duration_ms":1500,"planned":3,"applied":2,"failed":1}`)))
+	require.NoError(t, w.Add(runstore.GoalFile, []byte(`{"goal":[["POLYANET","SPACE"],["SPACE","RED_SOLOON"]]}`)))
+	require.NoError(t, w.Add(runstore.FinalFile, []byte(`{"goal":[["POLYANET","SPACE"],["SPACE","SPACE"]]}`)))
+	require.NoError(t, w.Add(AuditFile, []byte("{\"seq\":4,\"method\":\"POST\",\"status\":200}\n{\"seq\":5,\"method\":\"POST\",\"status\":429}\n")))
+	require.NoError(t, w.Add(runstore.LogFile, []byte("level=INFO msg=a\nlevel=WARN msg=b\n")))
+	w.Missing(runstore.JournalFile)
+	require.NoError(t, w.Close())
+	return archive.Bytes()
+}
+
+func TestRoundTripAndSummary(t *testing.T) {
+	b, err := Read(bytes.NewReader(writeTestBundle(t)))
+	require.NoError(t, err)
+	require.Equal(t, "run-1", b.Manifest.RunID)
+	require.Equal(t, []string{runstore.JournalFile}, b.Manifest.Missing)
+	require.Len(t, b.Names(), 5)
+
+	summary := Summarize(b)
+	require.Equal(t, 2, summary.Report.Applied)
+	require.Nil(t, summary.Journal)
+	require.Equal(t, 2, summary.Goal.Objects)
+	require.Equal(t, 1, summary.Mismatches)
+	require.Equal(t, 2, summary.Audit.Methods["POST"])
+	require.Equal(t, 1, summary.Audit.Failures)
+	require.Equal(t, 1, summary.Logs.Warnings)
+}
+
+func TestReadRejectsDamagedArchive(t *testing.T) {
+	data := writeTestBundle(t)
+
+	_, err := Read(bytes.NewReader(data[:len(data)/2]))
+	require.Error(t, err)
+
+	_, err = Read(bytes.NewReader([]byte("not an archive")))
+	require.Error(t, err)
+}
@@ -0,0 +1,210 @@
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/runstore"
+	"github.com/crossmint/megaverse-challenge/pkg/audit"
+)
+
+// Summary is what inspect reports about a bundle. Sections whose file is absent or unreadable
+// are left nil.
+type Summary struct {
+	Report  *ReportSummary
+	Journal *JournalSummary
+	Goal    *GridSummary
+	Final   *GridSummary
+	// Mismatches counts cells where the final grid differs from the goal; -1 when either is absent
+	Mismatches int
+	Audit      *AuditSummary
+	Logs       *LogSummary
+	Settings   int
+}
+
+// ReportSummary holds the counters of the execution report
+type ReportSummary struct {
+	Name     string
+	Planned  int
+	Applied  int
+	Failed   int
+	Skipped  int
+	Duration time.Duration
+}
+
+// JournalSummary counts journal entries by event
+type JournalSummary struct {
+	Entries int
+	Events  map[string]int
+}
+
+// GridSummary describes a grid snapshot
+type GridSummary struct {
+	Width, Height int
+	Objects       int
+}
+
+// AuditSummary counts the audited requests of the run
+type AuditSummary struct {
+	Requests int
+	Methods  map[string]int
+	Failures int // attempts that got no response or a 4xx/5xx status
+}
+
+// LogSummary counts log lines by severity
+type LogSummary struct {
+	Lines    int
+	Warnings int
+	Errors   int
+}
+
+// Summarize reads the known artifacts of b
+func Summarize(b *Bundle) Summary {
+	summary := Summary{Mismatches: -1}
+
+	if data, ok := b.Files[runstore.ReportFile]; ok {
+		var report struct {
+			Name       string `json:"name"`
+			DurationMS int64  `json:"duration_ms"`
+			Planned    int    `json:"planned"`
+			Applied    int    `json:"applied"`
+			Failed     int    `json:"failed"`
+			Skipped    int    `json:"skipped"`
+		}
+		if json.Unmarshal(data, &report) == nil {
+			summary.Report = &ReportSummary{
+				Name:     report.Name,
+				Planned:  report.Planned,
+				Applied:  report.Applied,
+				Failed:   report.Failed,
+				Skipped:  report.Skipped,
+				Duration: time.Duration(report.DurationMS) * time.Millisecond,
+			}
+		}
+	}
+
+	if data, ok := b.Files[runstore.JournalFile]; ok {
+		journal := &JournalSummary{Events: make(map[string]int)}
+		eachLine(data, func(line []byte) {
+			var entry domain.JournalEntry
+			if json.Unmarshal(line, &entry) == nil {
+				journal.Entries++
+				journal.Events[entry.Event]++
+			}
+		})
+		summary.Journal = journal
+	}
+
+	goal := readGrid(b.Files[runstore.GoalFile])
+	final := readGrid(b.Files[runstore.FinalFile])
+	summary.Goal, summary.Final = describeGrid(goal), describeGrid(final)
+	if goal != nil && final != nil {
+		summary.Mismatches = mismatches(goal, final)
+	}
+
+	if data, ok := b.Files[AuditFile]; ok {
+		requests := &AuditSummary{Methods: make(map[string]int)}
+		eachLine(data, func(line []byte) {
+			var entry audit.Entry
+			if json.Unmarshal(line, &entry) != nil {
+				return
+			}
+			requests.Requests++
+			requests.Methods[entry.Method]++
+			if entry.Status == 0 || entry.Status >= 400 {
+				requests.Failures++
+			}
+		})
+		summary.Audit = requests
+	}
+
+	if data, ok := b.Files[runstore.LogFile]; ok {
+		logs := &LogSummary{}
+		eachLine(data, func(line []byte) {
+			logs.Lines++
+			switch {
+			case bytes.Contains(line, []byte("level=WARN")), bytes.Contains(line, []byte(`"level":"WARN"`)):
+				logs.Warnings++
+			case bytes.Contains(line, []byte("level=ERROR")), bytes.Contains(line, []byte(`"level":"ERROR"`)):
+				logs.Errors++
+			}
+		})
+		summary.Logs = logs
+	}
+
+	if data, ok := b.Files[runstore.ConfigFile]; ok {
+		var settings []json.RawMessage
+		if json.Unmarshal(data, &settings) == nil {
+			summary.Settings = len(settings)
+		}
+	}
+
+	return summary
+}
+
+func eachLine(data []byte, fn func(line []byte)) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+			fn(scanner.Bytes())
+		}
+	}
+}
+
+func readGrid(data []byte) [][]string {
+	if data == nil {
+		return nil
+	}
+	var goal domain.GoalMap
+	if json.Unmarshal(data, &goal) != nil {
+		return nil
+	}
+	return goal.Goal
+}
+
+func describeGrid(grid [][]string) *GridSummary {
+	if grid == nil {
+		return nil
+	}
+	summary := &GridSummary{Height: len(grid)}
+	for _, row := range grid {
+		summary.Width = max(summary.Width, len(row))
+		for _, cell := range row {
+			if cell != "" && cell != "SPACE" {
+				summary.Objects++
+			}
+		}
+	}
+	return summary
+}
+
+func mismatches(goal, final [][]string) int {
+	cell := func(grid [][]string, row, col int) string {
+		if row < len(grid) && col < len(grid[row]) && grid[row][col] != "" {
+			return grid[row][col]
+		}
+		return "SPACE"
+	}
+
+	height := max(len(goal), len(final))
+	count := 0
+	for row := 0; row < height; row++ {
+		width := 0
+		if row < len(goal) {
+			width = len(goal[row])
+		}
+		if row < len(final) {
+			width = max(width, len(final[row]))
+		}
+		for col := 0; col < width; col++ {
+			if cell(goal, row, col) != cell(final, row, col) {
+				count++
+			}
+		}
+	}
+	return count
+}
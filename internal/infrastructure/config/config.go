@@ -2,9 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/crossmint/megaverse-challenge/pkg/breaker"
 	"github.com/crossmint/megaverse-challenge/pkg/retry"
 	"github.com/spf13/viper"
 )
@@ -14,15 +17,28 @@ type Config struct {
 	API       APIConfig       `mapstructure:"api"`
 	Logging   LoggingConfig   `mapstructure:"logging"`
 	Execution ExecutionConfig `mapstructure:"execution"`
+	Audit     AuditConfig     `mapstructure:"audit"`
+	Cache     CacheConfig     `mapstructure:"cache"`
 }
 
 // APIConfig contains API-related configuration
 type APIConfig struct {
-	BaseURL         string          `mapstructure:"base_url"`
-	CandidateID     string          `mapstructure:"candidate_id"`
-	Timeout         time.Duration   `mapstructure:"timeout"`
-	RetryConfig     RetryConfig     `mapstructure:"retry"`
-	RateLimitConfig RateLimitConfig `mapstructure:"rate_limit"`
+	BaseURL            string               `mapstructure:"base_url"`
+	BaseURLs           []string             `mapstructure:"base_urls"` // tried in order with failover; overrides base_url
+	Failover           FailoverConfig       `mapstructure:"failover"`
+	Endpoints          EndpointsConfig      `mapstructure:"endpoints"`
+	CandidateID        string               `mapstructure:"candidate_id"`
+	Timeout            time.Duration        `mapstructure:"timeout"`         // whole call, retries included
+	AttemptTimeout     time.Duration        `mapstructure:"attempt_timeout"` // each attempt; 0 uses timeout only
+	Transport          TransportConfig      `mapstructure:"transport"`
+	ProxyURL           string               `mapstructure:"proxy_url"`            // empty honours HTTP(S)_PROXY
+	CAFile             string               `mapstructure:"ca_file"`              // PEM certificates trusted besides the system roots
+	CertFile           string               `mapstructure:"cert_file"`            // client certificate, with key_file
+	KeyFile            string               `mapstructure:"key_file"`             // client certificate key, with cert_file
+	InsecureSkipVerify bool                 `mapstructure:"insecure_skip_verify"` // accept any server certificate; debugging only
+	RetryConfig        RetryConfig          `mapstructure:"retry"`
+	RateLimitConfig    RateLimitConfig      `mapstructure:"rate_limit"`
+	CircuitBreaker     CircuitBreakerConfig `mapstructure:"circuit_breaker"`
 }
 
 // RetryConfig contains retry-related configuration
@@ -38,26 +54,103 @@ type RateLimitConfig struct {
 	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
 }
 
+// TransportConfig contains HTTP connection tuning
+type TransportConfig struct {
+	MaxIdleConnsPerHost int           `mapstructure:"max_idle_conns_per_host"`
+	IdleConnTimeout     time.Duration `mapstructure:"idle_conn_timeout"`
+	TLSHandshakeTimeout time.Duration `mapstructure:"tls_handshake_timeout"`
+	KeepAlive           time.Duration `mapstructure:"keep_alive"` // TCP keep-alive period; negative disables probes
+	HTTP2               bool          `mapstructure:"http2"`
+}
+
+// FailoverConfig contains base URL failover configuration
+type FailoverConfig struct {
+	// Failures is how many 5xx responses in a row move requests to the next base URL
+	Failures int `mapstructure:"failures"`
+	// Cooldown is how long a failing base URL is skipped before a request probes it again
+	Cooldown time.Duration `mapstructure:"cooldown"`
+}
+
+// EndpointsConfig contains API path templates; empty fields keep the Crossmint API defaults
+type EndpointsConfig struct {
+	GoalMap    string `mapstructure:"goal_map"`
+	CurrentMap string `mapstructure:"current_map"`
+	// Objects is keyed by object type: polyanet, soloon, or cometh
+	Objects map[string]ObjectEndpointsConfig `mapstructure:"objects"`
+}
+
+// ObjectEndpointsConfig contains the requests that create and delete one object type
+type ObjectEndpointsConfig struct {
+	Create EndpointConfig `mapstructure:"create"`
+	Delete EndpointConfig `mapstructure:"delete"`
+}
+
+// EndpointConfig contains one templated request
+type EndpointConfig struct {
+	Method string `mapstructure:"method"`
+	Path   string `mapstructure:"path"`
+	Body   string `mapstructure:"body"` // JSON template; placeholders such as {row} are expanded
+}
+
+// CircuitBreakerConfig contains circuit breaker configuration
+type CircuitBreakerConfig struct {
+	// FailureRatio opens the breaker once this share of requests in Window failed; 0 disables it
+	FailureRatio float64       `mapstructure:"failure_ratio"`
+	Window       time.Duration `mapstructure:"window"`
+	MinRequests  int           `mapstructure:"min_requests"`
+	Cooldown     time.Duration `mapstructure:"cooldown"`
+}
+
 // LoggingConfig contains logging-related configuration
 type LoggingConfig struct {
 	Level  string `mapstructure:"level"`
 	Format string `mapstructure:"format"`
+
+	// HTTPBodyLimit caps request and response bodies logged by --debug-http, in bytes
+	HTTPBodyLimit int `mapstructure:"http_body_limit"`
 }
 
 // ExecutionConfig contains execution-related configuration
 type ExecutionConfig struct {
-	MaxWorkers int           `mapstructure:"max_workers"`
-	BatchSize  int           `mapstructure:"batch_size"`
-	Timeout    time.Duration `mapstructure:"timeout"`
+	MaxWorkers    int           `mapstructure:"max_workers"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	Timeout       time.Duration `mapstructure:"timeout"`
+	Order         string        `mapstructure:"order"`
+	BatchCooldown time.Duration `mapstructure:"batch_cooldown"`
+	VerifyBatches bool          `mapstructure:"verify_batches"`
+	RunsDir       string        `mapstructure:"runs_dir"`
+}
+
+// CacheConfig contains goal map cache configuration
+type CacheConfig struct {
+	// Dir holds cached goal maps, one per candidate ID and goal map URL; empty disables the cache
+	Dir string `mapstructure:"dir"`
+	// GoalTTL is how long a cached goal map is used without asking the API. The default of 0
+	// revalidates every lookup, since the goal changes once phase 1 is complete.
+	GoalTTL time.Duration `mapstructure:"goal_ttl"`
+}
+
+// AuditConfig contains audit log configuration
+type AuditConfig struct {
+	// Path of the hash-chained log of POST and DELETE requests; empty disables auditing
+	Path string `mapstructure:"path"`
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		API: APIConfig{
-			BaseURL:     "https://challenge.crossmint.io/api",
-			CandidateID: "",
-			Timeout:     30 * time.Second,
+			BaseURL:        "https://challenge.crossmint.io/api",
+			CandidateID:    "",
+			Timeout:        2 * time.Minute,
+			AttemptTimeout: 30 * time.Second,
+			Transport: TransportConfig{
+				MaxIdleConnsPerHost: 10,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: 10 * time.Second,
+				KeepAlive:           30 * time.Second,
+				HTTP2:               true,
+			},
 			RetryConfig: RetryConfig{
 				MaxAttempts:  6,
 				InitialDelay: 1 * time.Second,
@@ -67,15 +160,33 @@ func DefaultConfig() *Config {
 			RateLimitConfig: RateLimitConfig{
 				RequestsPerSecond: 2.0,
 			},
+			CircuitBreaker: CircuitBreakerConfig{
+				FailureRatio: 0.5,
+				Window:       30 * time.Second,
+				MinRequests:  10,
+				Cooldown:     15 * time.Second,
+			},
+			Failover: FailoverConfig{
+				Failures: 3,
+				Cooldown: 30 * time.Second,
+			},
 		},
 		Logging: LoggingConfig{
-			Level:  "info",
-			Format: "text",
+			Level:         "info",
+			Format:        "text",
+			HTTPBodyLimit: 4096,
 		},
 		Execution: ExecutionConfig{
 			MaxWorkers: 5,
 			BatchSize:  5,
 			Timeout:    5 * time.Minute,
+			RunsDir:    ".megaverse/runs",
+		},
+		Audit: AuditConfig{
+			Path: ".megaverse/audit.jsonl",
+		},
+		Cache: CacheConfig{
+			Dir: ".megaverse/cache",
 		},
 	}
 }
@@ -115,6 +226,7 @@ func Load() (*Config, error) {
 
 	if baseURL := os.Getenv("CROSSMINT_API_URL"); baseURL != "" {
 		cfg.API.BaseURL = baseURL
+		cfg.API.BaseURLs = nil
 	}
 
 	// Validate configuration
@@ -153,10 +265,20 @@ func LoadFromFile(path string) (*Config, error) {
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	if c.API.BaseURL == "" {
+	if c.API.BaseURL == "" && len(c.API.BaseURLs) == 0 {
 		return fmt.Errorf("API base URL is required")
 	}
 
+	for _, baseURL := range c.API.BaseURLs {
+		if baseURL == "" {
+			return fmt.Errorf("API base_urls must not contain empty entries")
+		}
+	}
+
+	if c.API.Failover.Failures < 0 || c.API.Failover.Cooldown < 0 {
+		return fmt.Errorf("API failover failures and cooldown must not be negative")
+	}
+
 	if c.API.CandidateID == "" {
 		return fmt.Errorf("candidate ID is required - set CROSSMINT_CANDIDATE_ID environment variable or add to config file")
 	}
@@ -165,6 +287,30 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("API timeout must be positive")
 	}
 
+	if c.API.AttemptTimeout < 0 {
+		return fmt.Errorf("API attempt timeout must not be negative")
+	}
+
+	if t := c.API.Transport; t.MaxIdleConnsPerHost < 0 || t.IdleConnTimeout < 0 || t.TLSHandshakeTimeout < 0 {
+		return fmt.Errorf("API transport settings must not be negative")
+	}
+
+	if c.API.ProxyURL != "" {
+		proxy, err := url.Parse(c.API.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return fmt.Errorf("API proxy_url must be an absolute URL such as http://proxy:3128")
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("API proxy_url scheme must be http, https, or socks5")
+		}
+	}
+
+	if (c.API.CertFile == "") != (c.API.KeyFile == "") {
+		return fmt.Errorf("API cert_file and key_file must be set together")
+	}
+
 	if c.API.RetryConfig.MaxAttempts <= 0 {
 		return fmt.Errorf("retry max attempts must be positive")
 	}
@@ -173,13 +319,58 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("rate limit must be positive")
 	}
 
+	if cb := c.API.CircuitBreaker; cb.FailureRatio < 0 || cb.FailureRatio > 1 {
+		return fmt.Errorf("circuit breaker failure_ratio must be between 0 and 1")
+	} else if cb.Window < 0 || cb.Cooldown < 0 || cb.MinRequests < 0 {
+		return fmt.Errorf("circuit breaker window, min_requests, and cooldown must not be negative")
+	}
+
 	if c.Execution.Timeout <= 0 {
 		return fmt.Errorf("execution timeout must be positive")
 	}
 
+	// The names accepted by strategies.ParseExecutionOrder, which main.go uses to apply the order
+	switch strings.ToLower(strings.TrimSpace(c.Execution.Order)) {
+	case "", "sequential", "parallel", "batched":
+	default:
+		return fmt.Errorf("execution order must be sequential, parallel, or batched")
+	}
+
+	switch strings.ToLower(c.Logging.Level) {
+	case "", "debug", "info", "warn", "warning", "error":
+	default:
+		return fmt.Errorf("logging level must be debug, info, warn, or error")
+	}
+
+	switch strings.ToLower(c.Logging.Format) {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("logging format must be text or json")
+	}
+
+	if c.Logging.HTTPBodyLimit < 0 {
+		return fmt.Errorf("logging http_body_limit must not be negative")
+	}
+
+	if c.Execution.BatchCooldown < 0 {
+		return fmt.Errorf("batch cooldown must not be negative")
+	}
+
+	if c.Cache.GoalTTL < 0 {
+		return fmt.Errorf("cache goal_ttl must not be negative")
+	}
+
 	return nil
 }
 
+// ResolvedBaseURLs returns the base URLs to use in order: base_urls when set, base_url otherwise
+func (a APIConfig) ResolvedBaseURLs() []string {
+	if len(a.BaseURLs) > 0 {
+		return a.BaseURLs
+	}
+	return []string{a.BaseURL}
+}
+
 // ToRetryConfig converts the retry configuration to the retry package format
 func (r RetryConfig) ToRetryConfig() retry.Config {
 	return retry.Config{
@@ -190,11 +381,23 @@ func (r RetryConfig) ToRetryConfig() retry.Config {
 	}
 }
 
+// ToBreakerConfig converts the circuit breaker configuration to the breaker package format
+func (c CircuitBreakerConfig) ToBreakerConfig() breaker.Config {
+	return breaker.Config{
+		FailureRatio: c.FailureRatio,
+		Window:       c.Window,
+		MinRequests:  c.MinRequests,
+		Cooldown:     c.Cooldown,
+	}
+}
+
 // Save saves the configuration to a file
 func (c *Config) Save(path string) error {
 	viper.Set("api", c.API)
 	viper.Set("logging", c.Logging)
 	viper.Set("execution", c.Execution)
+	viper.Set("audit", c.Audit)
+	viper.Set("cache", c.Cache)
 
 	return viper.WriteConfigAs(path)
 }
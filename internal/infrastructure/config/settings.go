@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Setting is one effective configuration value, keyed by its dotted configuration path
+type Setting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// secretKeys lists settings that must never be shown in full
+var secretKeys = map[string]bool{
+	"api.candidate_id": true,
+}
+
+// urlKeys lists settings holding URLs whose passwords must never be shown
+var urlKeys = map[string]bool{
+	"api.proxy_url": true,
+}
+
+// Settings lists every configuration value in declaration order with secrets redacted,
+// so the effective configuration can be shared in reports
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	flattenSettings("", reflect.ValueOf(*c), &settings)
+	return settings
+}
+
+func flattenSettings(prefix string, value reflect.Value, settings *[]Setting) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || !field.IsExported() {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() == reflect.Struct {
+			flattenSettings(key, fieldValue, settings)
+			continue
+		}
+
+		text := fmt.Sprint(fieldValue.Interface())
+		if secretKeys[key] {
+			text = Redact(text)
+		}
+		if urlKeys[key] {
+			text = redactURL(text)
+		}
+		*settings = append(*settings, Setting{Key: key, Value: text})
+	}
+}
+
+// Redact hides all but the last four characters of secret
+func Redact(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", len(secret)-4) + secret[len(secret)-4:]
+}
+
+// redactURL hides the password of a URL; values that do not parse are hidden entirely
+func redactURL(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Redact(raw)
+	}
+	return u.Redacted()
+}
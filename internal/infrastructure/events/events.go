@@ -0,0 +1,182 @@
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/api"
+)
+
+// SchemaVersion is bumped whenever a field is removed or changes meaning. Adding fields or event
+// types is backwards compatible and does not change the version.
+const SchemaVersion = 1
+
+// Event types
+const (
+	TypeRunStarted    = "run.started"
+	TypePlanGenerated = "plan.generated"
+	TypeObjectStarted = "object.started"
+	TypeObjectRetry   = "object.retry"
+	TypeObjectDone    = "object.done"
+	TypeObjectFailed  = "object.failed"
+	TypeObjectSkipped = "object.skipped"
+	TypeRunFinished   = "run.finished"
+	TypeRunSummary    = "run.summary"
+)
+
+// Event is one line of the stream. Every event carries the schema version, a sequence number,
+// the time, its type, and the run ID; the remaining fields depend on the type.
+type Event struct {
+	Version     int       `json:"v"`
+	Seq         int64     `json:"seq"`
+	Time        time.Time `json:"time"`
+	Type        string    `json:"type"`
+	RunID       string    `json:"run_id,omitempty"`
+	OperationID string    `json:"op_id,omitempty"`
+
+	// run.started, run.finished, run.summary
+	Name string `json:"name,omitempty"`
+	// run.started
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+
+	// plan.generated; nil when the plan is streamed and its size unknown
+	Objects *int `json:"objects,omitempty"`
+
+	// object.*
+	Operation string `json:"operation,omitempty"`
+	Row       *int   `json:"row,omitempty"`
+	Column    *int   `json:"column,omitempty"`
+	Object    string `json:"object,omitempty"`
+	Previous  string `json:"previous,omitempty"`
+
+	// object.retry
+	Method   string `json:"method,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Attempt  int    `json:"attempt,omitempty"`
+
+	// object.failed, run.finished
+	Error string `json:"error,omitempty"`
+
+	// run.summary
+	Planned    *int   `json:"planned,omitempty"`
+	Applied    *int   `json:"applied,omitempty"`
+	Failed     *int   `json:"failed,omitempty"`
+	Skipped    *int   `json:"skipped,omitempty"`
+	DurationMS *int64 `json:"duration_ms,omitempty"`
+}
+
+// Summary holds the counters of a finished run
+type Summary struct {
+	RunID    string
+	Name     string
+	Planned  int
+	Applied  int
+	Failed   int
+	Skipped  int
+	Duration time.Duration
+}
+
+// journalTypes maps journal events onto stream event types
+var journalTypes = map[string]string{
+	domain.JournalRunStarted:    TypeRunStarted,
+	domain.JournalPlanGenerated: TypePlanGenerated,
+	domain.JournalObjectStarted: TypeObjectStarted,
+	domain.JournalObjectApplied: TypeObjectDone,
+	domain.JournalObjectFailed:  TypeObjectFailed,
+	domain.JournalObjectSkipped: TypeObjectSkipped,
+	domain.JournalRunFinished:   TypeRunFinished,
+}
+
+// Emitter writes events as newline-delimited JSON. It is safe for concurrent use and doubles as a
+// domain.Journal, so it can be attached to the service next to the run journal.
+type Emitter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	seq     int64
+}
+
+// NewEmitter returns an emitter writing to w
+func NewEmitter(w io.Writer) *Emitter {
+	return &Emitter{encoder: json.NewEncoder(w)}
+}
+
+// Append translates a journal entry into an event
+func (e *Emitter) Append(entry domain.JournalEntry) error {
+	eventType, ok := journalTypes[entry.Event]
+	if !ok {
+		return nil
+	}
+
+	event := Event{
+		Time:        entry.Time,
+		Type:        eventType,
+		RunID:       entry.RunID,
+		OperationID: entry.OperationID,
+		Name:        entry.Name,
+		Width:       entry.Width,
+		Height:      entry.Height,
+		Operation:   entry.Operation,
+		Object:      entry.Object,
+		Previous:    entry.Previous,
+		Error:       entry.Error,
+	}
+	if entry.Position != nil {
+		event.Row, event.Column = &entry.Position.Row, &entry.Position.Column
+	}
+	if entry.Event == domain.JournalPlanGenerated && entry.Objects >= 0 {
+		objects := entry.Objects
+		event.Objects = &objects
+	}
+
+	return e.emit(event)
+}
+
+// ObserveRequest emits an object.retry event for every retried attempt; it satisfies api.RequestObserver
+func (e *Emitter) ObserveRequest(record api.RequestRecord) {
+	if record.Attempt <= 1 {
+		return
+	}
+	_ = e.emit(Event{
+		Time:        record.Start,
+		Type:        TypeObjectRetry,
+		RunID:       record.RunID,
+		OperationID: record.OperationID,
+		Method:      record.Method,
+		Endpoint:    record.Endpoint,
+		Attempt:     record.Attempt,
+	})
+}
+
+// Summary emits the run.summary event that closes a stream
+func (e *Emitter) Summary(summary Summary) error {
+	duration := summary.Duration.Milliseconds()
+	return e.emit(Event{
+		Time:       time.Now(),
+		Type:       TypeRunSummary,
+		RunID:      summary.RunID,
+		Name:       summary.Name,
+		Planned:    &summary.Planned,
+		Applied:    &summary.Applied,
+		Failed:     &summary.Failed,
+		Skipped:    &summary.Skipped,
+		DurationMS: &duration,
+	})
+}
+
+func (e *Emitter) emit(event Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.seq++
+	event.Version = SchemaVersion
+	event.Seq = e.seq
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	event.Time = event.Time.UTC()
+	return e.encoder.Encode(event)
+}
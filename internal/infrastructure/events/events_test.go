@@ -0,0 +1,59 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/api"
+)
+
+func decode(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var events []map[string]any
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var event map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestEmitterWritesVersionedEvents(t *testing.T) {
+	var buf bytes.Buffer
+	emitter := NewEmitter(&buf)
+
+	pos := entities.Position{Row: 0, Column: 4}
+	require.NoError(t, emitter.Append(domain.JournalEntry{RunID: "run-1", Event: domain.JournalPlanGenerated, Objects: 0}))
+	require.NoError(t, emitter.Append(domain.JournalEntry{RunID: "run-1", OperationID: "op-1", Event: domain.JournalObjectStarted, Operation: "create", Position: &pos, Object: "POLYANET"}))
+	emitter.ObserveRequest(api.RequestRecord{RunID: "run-1", OperationID: "op-1", Attempt: 1, Status: 429})
+	emitter.ObserveRequest(api.RequestRecord{RunID: "run-1", OperationID: "op-1", Attempt: 2, Method: "POST", Endpoint: "/polyanets"})
+	require.NoError(t, emitter.Append(domain.JournalEntry{RunID: "run-1", OperationID: "op-1", Event: domain.JournalObjectApplied, Position: &pos}))
+	require.NoError(t, emitter.Summary(Summary{RunID: "run-1", Name: "row", Planned: 1, Applied: 1, Duration: 1500 * time.Millisecond}))
+
+	events := decode(t, &buf)
+	require.Len(t, events, 5)
+
+	var types []string
+	for i, event := range events {
+		require.EqualValues(t, SchemaVersion, event["v"])
+		require.EqualValues(t, i+1, event["seq"])
+		require.Equal(t, "run-1", event["run_id"])
+		types = append(types, event["type"].(string))
+	}
+	require.Equal(t, []string{TypePlanGenerated, TypeObjectStarted, TypeObjectRetry, TypeObjectDone, TypeRunSummary}, types)
+
+	require.EqualValues(t, 0, events[0]["objects"], "an empty plan still reports its size")
+	require.EqualValues(t, 0, events[1]["row"])
+	require.EqualValues(t, 4, events[1]["column"])
+	require.EqualValues(t, 2, events[2]["attempt"])
+	require.EqualValues(t, 0, events[4]["failed"])
+	require.EqualValues(t, 1500, events[4]["duration_ms"])
+}
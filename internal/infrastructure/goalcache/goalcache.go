@@ -0,0 +1,99 @@
+package goalcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+)
+
+// Entry is a cached goal map with the validators the API sent along with it
+type Entry struct {
+	Goal         domain.GoalMap `json:"goal"`
+	ETag         string         `json:"etag,omitempty"`
+	LastModified string         `json:"last_modified,omitempty"`
+	// FetchedAt is when the API last returned or confirmed Goal
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Age returns how long ago the entry was fetched or confirmed
+func (e *Entry) Age(now time.Time) time.Duration {
+	return now.Sub(e.FetchedAt)
+}
+
+// Fresh reports whether the entry is younger than ttl and can be used without asking the API
+func (e *Entry) Fresh(ttl time.Duration, now time.Time) bool {
+	return e.Age(now) < ttl
+}
+
+// Cache stores goal maps as JSON files under a directory, one per key. Callers key entries by
+// everything that selects the goal map, such as the candidate ID and the URL it is fetched from, so
+// switching API servers never serves another server's goal. File names are derived from a hash of
+// the key, so the directory listing does not reveal the candidate ID.
+type Cache struct {
+	dir string
+}
+
+// New returns a cache in dir; the directory is created on first write
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Dir returns the directory holding the cache
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Path returns the file caching the goal map of key
+func (c *Cache) Path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, "goal-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// Load returns the cached entry of key. A missing entry is reported as an error matching
+// os.ErrNotExist.
+func (c *Cache) Load(key string) (*Entry, error) {
+	data, err := os.ReadFile(c.Path(key))
+	if err != nil {
+		return nil, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("corrupt goal cache %s: %w", c.Path(key), err)
+	}
+	return &entry, nil
+}
+
+// Save replaces the cached entry of key. The file is written next to its final name and
+// renamed into place, so concurrent readers never see a partial entry.
+func (c *Cache) Save(key string, entry Entry) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create goal cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode goal cache: %w", err)
+	}
+
+	file, err := os.CreateTemp(c.dir, ".goal-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write goal cache: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write goal cache: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write goal cache: %w", err)
+	}
+	if err := os.Rename(file.Name(), c.Path(key)); err != nil {
+		return fmt.Errorf("failed to write goal cache: %w", err)
+	}
+	return nil
+}
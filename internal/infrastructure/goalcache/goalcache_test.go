@@ -0,0 +1,53 @@
+package goalcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	cache := New(filepath.Join(t.TempDir(), "cache"))
+
+	_, err := cache.Load("cand-1")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	fetched := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	entry := Entry{Goal: domain.GoalMap{Goal: [][]string{{"SPACE", "POLYANET"}}}, ETag: `"v1"`, FetchedAt: fetched}
+	require.NoError(t, cache.Save("cand-1", entry))
+
+	loaded, err := cache.Load("cand-1")
+	require.NoError(t, err)
+	require.Equal(t, entry.Goal, loaded.Goal)
+	require.Equal(t, `"v1"`, loaded.ETag)
+	require.True(t, loaded.FetchedAt.Equal(fetched))
+
+	_, err = cache.Load("cand-2")
+	require.ErrorIs(t, err, os.ErrNotExist, "other keys have their own entries")
+	require.NotContains(t, cache.Path("cand-1"), "cand-1")
+
+	files, err := os.ReadDir(cache.Dir())
+	require.NoError(t, err)
+	require.Len(t, files, 1, "no temporary files are left behind")
+}
+
+func TestLoadRejectsCorruptEntries(t *testing.T) {
+	cache := New(t.TempDir())
+	require.NoError(t, os.WriteFile(cache.Path("cand-1"), []byte("{"), 0o644))
+
+	_, err := cache.Load("cand-1")
+	require.ErrorContains(t, err, "corrupt goal cache")
+}
+
+func TestFresh(t *testing.T) {
+	now := time.Now()
+	entry := Entry{FetchedAt: now.Add(-time.Minute)}
+	require.True(t, entry.Fresh(time.Hour, now))
+	require.False(t, entry.Fresh(time.Minute, now))
+	require.False(t, entry.Fresh(0, now), "a zero TTL always revalidates")
+}
@@ -0,0 +1,103 @@
+package goalfile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+)
+
+// Load reads a goal map from a local JSON file using the same shape as the API's goal endpoint
+func Load(path string) (*domain.GoalMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read goal file: %w", err)
+	}
+
+	var goalMap domain.GoalMap
+	if err := json.Unmarshal(data, &goalMap); err != nil {
+		return nil, fmt.Errorf("failed to parse goal file %s: %w", path, err)
+	}
+
+	if len(goalMap.Goal) == 0 {
+		return nil, fmt.Errorf("goal file %s is empty", path)
+	}
+
+	return &goalMap, nil
+}
+
+// Watch signals on the returned channel whenever the goal file is written, created, or replaced.
+// Bursts of events are coalesced so one save produces a single notification.
+func Watch(ctx context.Context, path string) (<-chan struct{}, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve goal file path: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	// Watch the directory rather than the file: editors often save by renaming a temp file over the original.
+	if err := watcher.Add(filepath.Dir(absPath)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", filepath.Dir(absPath), err)
+	}
+
+	const debounce = 200 * time.Millisecond
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		defer watcher.Close()
+		defer close(changes)
+
+		var timer *time.Timer
+		var fire <-chan time.Time
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != absPath {
+					continue
+				}
+				if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+					continue
+				}
+				if timer == nil {
+					timer = time.NewTimer(debounce)
+				} else {
+					timer.Reset(debounce)
+				}
+				fire = timer.C
+
+			case <-fire:
+				fire = nil
+				select {
+				case changes <- struct{}{}:
+				default:
+					// A change is already pending; the consumer will reload the latest contents anyway.
+				}
+
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return changes, nil
+}
@@ -0,0 +1,295 @@
+package htmlreport
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/api"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/config"
+)
+
+// Data is everything a report shows. Grids hold goal map tokens such as "POLYANET" or "RED_SOLOON".
+type Data struct {
+	Name       string
+	RunID      string
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	Planned int
+	Applied int
+	Failed  int
+	Skipped int
+
+	Goal  [][]string
+	Final [][]string
+
+	// Failures and Retries count failed object events and retried HTTP attempts per cell
+	Failures map[entities.Position]int
+	Retries  map[entities.Position]int
+
+	Requests []api.RequestRecord
+	Settings []config.Setting
+}
+
+// CellStats attributes failed objects from the journal and retried requests to grid cells.
+// Requests are matched to cells through the operation ID they share with journal entries.
+func CellStats(entries []domain.JournalEntry, requests []api.RequestRecord) (failures, retries map[entities.Position]int) {
+	failures = make(map[entities.Position]int)
+	retries = make(map[entities.Position]int)
+	positions := make(map[string]entities.Position)
+
+	for _, entry := range entries {
+		if entry.Position == nil {
+			continue
+		}
+		if entry.OperationID != "" {
+			positions[entry.OperationID] = *entry.Position
+		}
+		if entry.Event == domain.JournalObjectFailed {
+			failures[*entry.Position]++
+		}
+	}
+
+	for _, request := range requests {
+		if request.Attempt <= 1 {
+			continue
+		}
+		if pos, ok := positions[request.OperationID]; ok {
+			retries[pos]++
+		}
+	}
+
+	return failures, retries
+}
+
+// Write renders the report to path as a single self-contained HTML file
+func Write(path string, data Data) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	if err := Render(file, data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Render writes the report as HTML to w
+func Render(w io.Writer, data Data) error {
+	if err := page.Execute(w, newView(data)); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+type view struct {
+	Data
+	Duration    time.Duration
+	GoalGrid    [][]cellView
+	FinalGrid   [][]cellView
+	Heatmap     [][]heatCell
+	HeatTotal   int
+	Timeline    []requestView
+	Stats       requestStats
+	GeneratedAt time.Time
+}
+
+type cellView struct {
+	Symbol string
+	Title  string
+	Class  string
+}
+
+type heatCell struct {
+	Title string
+	Style template.CSS
+	Count int
+}
+
+type requestView struct {
+	api.RequestRecord
+	OffsetMS  int64
+	LatencyMS int64
+	Bar       template.CSS
+	Class     string
+}
+
+type requestStats struct {
+	Total       int
+	RateLimited int
+	Errors      int
+	Retries     int
+	P50         time.Duration
+	P95         time.Duration
+	Max         time.Duration
+}
+
+func newView(data Data) view {
+	v := view{
+		Data:        data,
+		Duration:    data.FinishedAt.Sub(data.StartedAt).Round(time.Millisecond),
+		GoalGrid:    cellGrid(data.Goal),
+		FinalGrid:   cellGrid(data.Final),
+		GeneratedAt: time.Now(),
+	}
+	v.Heatmap, v.HeatTotal = heatmap(data)
+	v.Timeline, v.Stats = timeline(data.Requests)
+	return v
+}
+
+func cellGrid(grid [][]string) [][]cellView {
+	cells := make([][]cellView, len(grid))
+	for row, values := range grid {
+		cells[row] = make([]cellView, len(values))
+		for col, value := range values {
+			symbol, class := sprite(value)
+			cells[row][col] = cellView{Symbol: symbol, Class: class, Title: fmt.Sprintf("%d,%d %s", row, col, value)}
+		}
+	}
+	return cells
+}
+
+// sprite returns the symbol and CSS class used to draw a goal map token
+func sprite(token string) (symbol, class string) {
+	switch token {
+	case "SPACE", "":
+		return "", "space"
+	case "POLYANET":
+		return "🪐", "object"
+	case "RED_SOLOON":
+		return "🔴", "object"
+	case "BLUE_SOLOON":
+		return "🔵", "object"
+	case "PURPLE_SOLOON":
+		return "🟣", "object"
+	case "WHITE_SOLOON":
+		return "⚪", "object"
+	case "UP_COMETH":
+		return "⬆", "object cometh"
+	case "DOWN_COMETH":
+		return "⬇", "object cometh"
+	case "LEFT_COMETH":
+		return "⬅", "object cometh"
+	case "RIGHT_COMETH":
+		return "➡", "object cometh"
+	default:
+		return "?", "unknown"
+	}
+}
+
+func heatmap(data Data) ([][]heatCell, int) {
+	height, width := gridSize(data.Goal)
+	if h, w := gridSize(data.Final); h > height || w > width {
+		height, width = max(height, h), max(width, w)
+	}
+	for _, counts := range []map[entities.Position]int{data.Failures, data.Retries} {
+		for pos := range counts {
+			height, width = max(height, pos.Row+1), max(width, pos.Column+1)
+		}
+	}
+
+	peak, total := 0, 0
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			pos := entities.Position{Row: row, Column: col}
+			n := data.Failures[pos] + data.Retries[pos]
+			peak = max(peak, n)
+			total += n
+		}
+	}
+
+	cells := make([][]heatCell, height)
+	for row := range cells {
+		cells[row] = make([]heatCell, width)
+		for col := range cells[row] {
+			pos := entities.Position{Row: row, Column: col}
+			failed, retried := data.Failures[pos], data.Retries[pos]
+			cell := heatCell{Count: failed + retried, Title: fmt.Sprintf("%d,%d: %d failed, %d retried", row, col, failed, retried)}
+			if cell.Count > 0 {
+				alpha := 0.2 + 0.8*float64(cell.Count)/float64(peak)
+				cell.Style = template.CSS(fmt.Sprintf("background: rgba(220, 53, 69, %.2f)", alpha))
+			}
+			cells[row][col] = cell
+		}
+	}
+	return cells, total
+}
+
+func gridSize(grid [][]string) (height, width int) {
+	if len(grid) == 0 {
+		return 0, 0
+	}
+	return len(grid), len(grid[0])
+}
+
+func timeline(requests []api.RequestRecord) ([]requestView, requestStats) {
+	sorted := append([]api.RequestRecord(nil), requests...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	stats := requestStats{Total: len(sorted)}
+	if len(sorted) == 0 {
+		return nil, stats
+	}
+
+	first := sorted[0].Start
+	var end time.Time
+	latencies := make([]time.Duration, 0, len(sorted))
+	for _, r := range sorted {
+		if finished := r.Start.Add(r.Duration); finished.After(end) {
+			end = finished
+		}
+		latencies = append(latencies, r.Duration)
+		switch {
+		case r.Status == 429:
+			stats.RateLimited++
+		case r.Status == 0 || r.Status >= 400:
+			stats.Errors++
+		}
+		if r.Attempt > 1 {
+			stats.Retries++
+		}
+	}
+	span := end.Sub(first)
+	if span <= 0 {
+		span = time.Millisecond
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	stats.P50 = percentile(latencies, 0.50)
+	stats.P95 = percentile(latencies, 0.95)
+	stats.Max = latencies[len(latencies)-1].Round(time.Millisecond)
+
+	views := make([]requestView, len(sorted))
+	for i, r := range sorted {
+		offset := r.Start.Sub(first)
+		class := "ok"
+		switch {
+		case r.Status == 429:
+			class = "throttled"
+		case r.Status == 0 || r.Status >= 400:
+			class = "error"
+		}
+		views[i] = requestView{
+			RequestRecord: r,
+			OffsetMS:      offset.Milliseconds(),
+			LatencyMS:     r.Duration.Milliseconds(),
+			Bar: template.CSS(fmt.Sprintf("left: %.2f%%; width: %.2f%%",
+				100*float64(offset)/float64(span), max(0.3, 100*float64(r.Duration)/float64(span)))),
+			Class: class,
+		}
+	}
+	return views, stats
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	index := int(float64(len(sorted)-1) * p)
+	return sorted[index].Round(time.Millisecond)
+}
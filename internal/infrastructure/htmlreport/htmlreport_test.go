@@ -0,0 +1,66 @@
+package htmlreport
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/api"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/config"
+)
+
+func TestCellStatsMatchesRequestsThroughOperationIDs(t *testing.T) {
+	pos := entities.Position{Row: 1, Column: 2}
+	entries := []domain.JournalEntry{
+		{OperationID: "op-1", Event: domain.JournalObjectStarted, Position: &pos},
+		{OperationID: "op-1", Event: domain.JournalObjectFailed, Position: &pos},
+	}
+	requests := []api.RequestRecord{
+		{OperationID: "op-1", Attempt: 1, Status: 429},
+		{OperationID: "op-1", Attempt: 2, Status: 429},
+		{OperationID: "op-2", Attempt: 2, Status: 500},
+	}
+
+	failures, retries := CellStats(entries, requests)
+	require.Equal(t, map[entities.Position]int{pos: 1}, failures)
+	require.Equal(t, map[entities.Position]int{pos: 1}, retries)
+}
+
+func TestRenderIncludesEverySection(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := config.DefaultConfig()
+	cfg.API.CandidateID = "candidate-secret-1234"
+
+	data := Data{
+		Name:       "Cross Pattern (Phase 1)",
+		RunID:      "run-1",
+		StartedAt:  start,
+		FinishedAt: start.Add(2 * time.Second),
+		Goal:       [][]string{{"POLYANET", "SPACE"}, {"RED_SOLOON", "UP_COMETH"}},
+		Final:      [][]string{{"POLYANET", "SPACE"}, {"SPACE", "UP_COMETH"}},
+		Failures:   map[entities.Position]int{{Row: 1, Column: 0}: 1},
+		Requests: []api.RequestRecord{
+			{Start: start, Duration: 40 * time.Millisecond, Method: "POST", Endpoint: "/polyanets", Attempt: 1, Status: 200},
+			{Start: start.Add(50 * time.Millisecond), Duration: 30 * time.Millisecond, Method: "POST", Endpoint: "/soloons", Attempt: 1, Status: 429},
+		},
+		Settings: cfg.Settings(),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, data))
+	html := buf.String()
+
+	require.Contains(t, html, "run-1")
+	require.Contains(t, html, "🪐")
+	require.Contains(t, html, "🔴")
+	require.Contains(t, html, "1,0: 1 failed, 0 retried")
+	require.Contains(t, html, `<tr class="throttled">`)
+	require.Contains(t, html, "<b>1</b></span><span>Errors")
+	require.Contains(t, html, "api.candidate_id")
+	require.Contains(t, html, "*****************1234")
+	require.NotContains(t, html, "candidate-secret")
+}
@@ -0,0 +1,82 @@
+package htmlreport
+
+import (
+	"html/template"
+	"strconv"
+	"time"
+)
+
+var page = template.Must(template.New("report").Funcs(template.FuncMap{
+	"timestamp": func(t time.Time) string { return t.Format(time.RFC3339) },
+	"status": func(status int) string {
+		if status == 0 {
+			return "error"
+		}
+		return strconv.Itoa(status)
+	},
+}).Parse(pageTemplate))
+
+// pageTemplate has no external assets so the report can be attached to a ticket and opened offline
+const pageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Megaverse run {{.RunID}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+h1 { margin-bottom: 0.2rem; }
+h2 { margin-top: 2rem; border-bottom: 1px solid #d0d7de; padding-bottom: 0.3rem; }
+.muted { color: #656d76; }
+.summary span { display: inline-block; margin-right: 1.5rem; }
+.grids { display: flex; flex-wrap: wrap; gap: 2rem; }
+table.grid { border-collapse: collapse; }
+table.grid td { width: 1.4rem; height: 1.4rem; text-align: center; font-size: 0.9rem; border: 1px solid #eaeef2; padding: 0; }
+table.grid td.cometh { color: #bc4c00; font-weight: bold; }
+table.grid td.unknown { background: #fff8c5; }
+table.data { border-collapse: collapse; font-size: 0.85rem; }
+table.data th, table.data td { border-bottom: 1px solid #eaeef2; padding: 0.2rem 0.6rem; text-align: left; }
+.track { position: relative; width: 320px; height: 0.8rem; background: #f6f8fa; }
+.bar { position: absolute; top: 0; height: 100%; background: #2da44e; }
+tr.throttled .bar { background: #bf8700; }
+tr.throttled { background: #fff8c5; }
+tr.error .bar { background: #cf222e; }
+tr.error { background: #ffebe9; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<p class="muted">Run {{.RunID}} &middot; started {{timestamp .StartedAt}} &middot; took {{.Duration}} &middot; report generated {{timestamp .GeneratedAt}}</p>
+<p class="summary"><span>Planned <b>{{.Planned}}</b></span><span>Applied <b>{{.Applied}}</b></span><span>Failed <b>{{.Failed}}</b></span><span>Skipped <b>{{.Skipped}}</b></span></p>
+
+<h2>Grids</h2>
+<div class="grids">
+<div>
+<h3>Goal</h3>
+{{if .GoalGrid}}<table class="grid">{{range .GoalGrid}}<tr>{{range .}}<td class="{{.Class}}" title="{{.Title}}">{{.Symbol}}</td>{{end}}</tr>{{end}}</table>{{else}}<p class="muted">Goal map unavailable.</p>{{end}}
+</div>
+<div>
+<h3>Final</h3>
+{{if .FinalGrid}}<table class="grid">{{range .FinalGrid}}<tr>{{range .}}<td class="{{.Class}}" title="{{.Title}}">{{.Symbol}}</td>{{end}}</tr>{{end}}</table>{{else}}<p class="muted">Current map unavailable.</p>{{end}}
+</div>
+<div>
+<h3>Failures and retries</h3>
+{{if .HeatTotal}}<table class="grid">{{range .Heatmap}}<tr>{{range .}}<td style="{{.Style}}" title="{{.Title}}">{{if .Count}}{{.Count}}{{end}}</td>{{end}}</tr>{{end}}</table>{{else}}<p class="muted">No failed or retried cells.</p>{{end}}
+</div>
+</div>
+
+<h2>Requests</h2>
+<p class="summary"><span>Total <b>{{.Stats.Total}}</b></span><span>429s <b>{{.Stats.RateLimited}}</b></span><span>Errors <b>{{.Stats.Errors}}</b></span><span>Retries <b>{{.Stats.Retries}}</b></span><span>p50 <b>{{.Stats.P50}}</b></span><span>p95 <b>{{.Stats.P95}}</b></span><span>max <b>{{.Stats.Max}}</b></span></p>
+{{if .Timeline}}
+<table class="data">
+<tr><th>+ms</th><th>Method</th><th>Endpoint</th><th>Attempt</th><th>Status</th><th>Latency</th><th>Limiter wait</th><th>Operation</th><th>Timeline</th></tr>
+{{range .Timeline}}<tr class="{{.Class}}"><td>{{.OffsetMS}}</td><td>{{.Method}}</td><td>{{.Endpoint}}</td><td>{{.Attempt}}</td><td title="{{.Error}}">{{status .Status}}</td><td>{{.LatencyMS}} ms</td><td>{{.LimiterWait}}</td><td>{{.OperationID}}</td><td><div class="track"><div class="bar" style="{{.Bar}}"></div></div></td></tr>
+{{end}}</table>
+{{else}}<p class="muted">No requests were recorded.</p>{{end}}
+
+<h2>Effective configuration</h2>
+<table class="data">
+{{range .Settings}}<tr><th>{{.Key}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`
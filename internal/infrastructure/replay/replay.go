@@ -0,0 +1,154 @@
+package replay
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+)
+
+// Options tunes the rendered animation
+type Options struct {
+	// CellSize is the width and height of one grid cell in pixels; values below 8 use 8
+	CellSize int
+	// FrameDelay is how long each change stays on screen; zero uses 100ms
+	FrameDelay time.Duration
+	// FinalDelay is how long the finished megaverse stays on screen before the animation loops
+	FinalDelay time.Duration
+}
+
+// Palette indices
+const (
+	colorBackground = iota
+	colorGrid
+	colorPolyanet
+	colorRing
+	colorRed
+	colorBlue
+	colorPurple
+	colorWhite
+	colorOutline
+	colorCometh
+	colorFailure
+)
+
+var palette = color.Palette{
+	colorBackground: color.RGBA{0x0d, 0x11, 0x27, 0xff},
+	colorGrid:       color.RGBA{0x1f, 0x26, 0x4a, 0xff},
+	colorPolyanet:   color.RGBA{0x9b, 0x87, 0xf5, 0xff},
+	colorRing:       color.RGBA{0xf5, 0xd0, 0x87, 0xff},
+	colorRed:        color.RGBA{0xe6, 0x39, 0x46, 0xff},
+	colorBlue:       color.RGBA{0x3a, 0x86, 0xff, 0xff},
+	colorPurple:     color.RGBA{0xa1, 0x4c, 0xd8, 0xff},
+	colorWhite:      color.RGBA{0xf1, 0xf1, 0xf1, 0xff},
+	colorOutline:    color.RGBA{0x80, 0x80, 0x80, 0xff},
+	colorCometh:     color.RGBA{0xff, 0x9f, 0x1c, 0xff},
+	colorFailure:    color.RGBA{0xff, 0x00, 0x3c, 0xff},
+}
+
+// Frames reports how many frames Render produces for entries, including the empty first frame
+func Frames(entries []domain.JournalEntry) int {
+	frames := 1
+	for _, entry := range entries {
+		if isChange(entry) {
+			frames++
+		}
+	}
+	return frames
+}
+
+func isChange(entry domain.JournalEntry) bool {
+	return entry.Position != nil && (entry.Event == domain.JournalObjectApplied || entry.Event == domain.JournalObjectFailed)
+}
+
+// Render writes an animated GIF that replays entries: it starts from an empty grid and adds one
+// frame per applied or failed object, in journal order. Cells whose dispatch failed get a red frame.
+func Render(w io.Writer, entries []domain.JournalEntry, opts Options) error {
+	width, height := gridSize(entries)
+	if width == 0 || height == 0 {
+		return fmt.Errorf("journal has no grid to replay")
+	}
+
+	cell := max(opts.CellSize, 8)
+	delay := opts.FrameDelay
+	if delay <= 0 {
+		delay = 100 * time.Millisecond
+	}
+	finalDelay := max(opts.FinalDelay, delay)
+
+	bounds := image.Rect(0, 0, width*cell, height*cell)
+	first := image.NewPaletted(bounds, palette)
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			drawCell(first, cellRect(row, col, cell), "", false)
+		}
+	}
+
+	animation := &gif.GIF{
+		Image:    []*image.Paletted{first},
+		Delay:    []int{centiseconds(delay)},
+		Disposal: []byte{gif.DisposalNone},
+		Config:   image.Config{ColorModel: palette, Width: bounds.Dx(), Height: bounds.Dy()},
+	}
+
+	cells := make(map[entities.Position]string)
+	failed := make(map[entities.Position]bool)
+
+	for _, entry := range entries {
+		if !isChange(entry) {
+			continue
+		}
+		pos := *entry.Position
+		if pos.Row < 0 || pos.Column < 0 || pos.Row >= height || pos.Column >= width {
+			continue
+		}
+
+		if entry.Event == domain.JournalObjectFailed {
+			failed[pos] = true
+		} else {
+			cells[pos] = entry.Object
+		}
+
+		// Only the changed cell is encoded; earlier frames stay on screen underneath it.
+		frame := image.NewPaletted(cellRect(pos.Row, pos.Column, cell), palette)
+		drawCell(frame, frame.Rect, cells[pos], failed[pos])
+
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, centiseconds(delay))
+		animation.Disposal = append(animation.Disposal, gif.DisposalNone)
+	}
+
+	animation.Delay[len(animation.Delay)-1] = centiseconds(finalDelay)
+
+	if err := gif.EncodeAll(w, animation); err != nil {
+		return fmt.Errorf("failed to encode replay: %w", err)
+	}
+	return nil
+}
+
+// gridSize uses the size recorded when the run started, growing it to fit every recorded position
+// for runs, such as operation lists, that do not know their grid up front
+func gridSize(entries []domain.JournalEntry) (width, height int) {
+	for _, entry := range entries {
+		if entry.Event == domain.JournalRunStarted {
+			width, height = max(width, entry.Width), max(height, entry.Height)
+		}
+		if entry.Position != nil {
+			width, height = max(width, entry.Position.Column+1), max(height, entry.Position.Row+1)
+		}
+	}
+	return width, height
+}
+
+func cellRect(row, col, cell int) image.Rectangle {
+	return image.Rect(col*cell, row*cell, (col+1)*cell, (row+1)*cell)
+}
+
+func centiseconds(d time.Duration) int {
+	return max(int(d/(10*time.Millisecond)), 1)
+}
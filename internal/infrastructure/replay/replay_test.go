@@ -0,0 +1,47 @@
+package replay
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+)
+
+func TestRenderAddsOneFramePerChange(t *testing.T) {
+	at := func(row, col int) *entities.Position { return &entities.Position{Row: row, Column: col} }
+	entries := []domain.JournalEntry{
+		{Event: domain.JournalRunStarted, Width: 3, Height: 2},
+		{Event: domain.JournalObjectStarted, Position: at(0, 0), Object: "POLYANET"},
+		{Event: domain.JournalObjectApplied, Position: at(0, 0), Object: "POLYANET"},
+		{Event: domain.JournalObjectApplied, Position: at(1, 2), Object: "RED_SOLOON"},
+		{Event: domain.JournalObjectFailed, Position: at(1, 1), Object: "UP_COMETH"},
+		{Event: domain.JournalRunFinished},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, entries, Options{CellSize: 10}))
+
+	decoded, err := gif.DecodeAll(&buf)
+	require.NoError(t, err)
+	require.Equal(t, Frames(entries), len(decoded.Image))
+	require.Len(t, decoded.Image, 4)
+	require.Equal(t, 30, decoded.Config.Width)
+	require.Equal(t, 20, decoded.Config.Height)
+
+	soloon := decoded.Image[2]
+	require.Equal(t, 10, soloon.Rect.Min.Y)
+	require.Equal(t, 20, soloon.Rect.Min.X)
+	require.Equal(t, uint8(colorRed), soloon.ColorIndexAt(25, 15))
+
+	failure := decoded.Image[3]
+	require.Equal(t, uint8(colorFailure), failure.ColorIndexAt(10, 10), "failed cells are framed in red")
+	require.Equal(t, uint8(colorBackground), failure.ColorIndexAt(15, 15), "a failed create leaves the cell empty")
+}
+
+func TestRenderRejectsEmptyJournal(t *testing.T) {
+	require.Error(t, Render(&bytes.Buffer{}, nil, Options{}))
+}
@@ -0,0 +1,137 @@
+package replay
+
+import (
+	"image"
+)
+
+// drawCell paints one grid cell: background, grid line, the sprite for token, and a red frame
+// when an attempt on the cell failed
+func drawCell(img *image.Paletted, r image.Rectangle, token string, failed bool) {
+	fill(img, r, colorBackground)
+	outline(img, r, 1, colorGrid)
+
+	size := r.Dx()
+	cx, cy := float64(r.Min.X)+float64(size)/2, float64(r.Min.Y)+float64(size)/2
+
+	switch token {
+	case "POLYANET":
+		drawPolyanet(img, r, cx, cy, float64(size))
+	case "RED_SOLOON":
+		drawSoloon(img, r, cx, cy, float64(size), colorRed)
+	case "BLUE_SOLOON":
+		drawSoloon(img, r, cx, cy, float64(size), colorBlue)
+	case "PURPLE_SOLOON":
+		drawSoloon(img, r, cx, cy, float64(size), colorPurple)
+	case "WHITE_SOLOON":
+		drawSoloon(img, r, cx, cy, float64(size), colorWhite)
+	case "UP_COMETH":
+		drawCometh(img, r, cx, cy, float64(size), 0, -1)
+	case "DOWN_COMETH":
+		drawCometh(img, r, cx, cy, float64(size), 0, 1)
+	case "LEFT_COMETH":
+		drawCometh(img, r, cx, cy, float64(size), -1, 0)
+	case "RIGHT_COMETH":
+		drawCometh(img, r, cx, cy, float64(size), 1, 0)
+	}
+
+	if failed {
+		outline(img, r, max(size/8, 1), colorFailure)
+	}
+}
+
+// drawPolyanet draws a ringed planet: the back of the ring, the planet, then the front of the ring
+func drawPolyanet(img *image.Paletted, r image.Rectangle, cx, cy, size float64) {
+	ring := func(front bool) {
+		each(r, func(x, y float64) uint8 {
+			dx, dy := (x-cx)/(0.48*size), (y-cy)/(0.17*size)
+			d := dx*dx + dy*dy
+			if d < 0.5 || d > 1 || (y >= cy) != front {
+				return 0
+			}
+			return colorRing
+		}, img)
+	}
+
+	ring(false)
+	each(r, func(x, y float64) uint8 {
+		if dist2(x, y, cx, cy) <= (0.28*size)*(0.28*size) {
+			return colorPolyanet
+		}
+		return 0
+	}, img)
+	ring(true)
+}
+
+func drawSoloon(img *image.Paletted, r image.Rectangle, cx, cy, size float64, shade uint8) {
+	radius := 0.34 * size
+	each(r, func(x, y float64) uint8 {
+		d := dist2(x, y, cx, cy)
+		switch {
+		case d > radius*radius:
+			return 0
+		case shade == colorWhite && d > (radius-1.2)*(radius-1.2):
+			return colorOutline
+		default:
+			return shade
+		}
+	}, img)
+}
+
+// drawCometh draws an arrow head pointing along (dx, dy)
+func drawCometh(img *image.Paletted, r image.Rectangle, cx, cy, size, dx, dy float64) {
+	// Tip, and the two back corners of the triangle, relative to the centre
+	tipX, tipY := cx+dx*0.38*size, cy+dy*0.38*size
+	backX, backY := cx-dx*0.3*size, cy-dy*0.3*size
+	leftX, leftY := backX-dy*0.32*size, backY+dx*0.32*size
+	rightX, rightY := backX+dy*0.32*size, backY-dx*0.32*size
+
+	each(r, func(x, y float64) uint8 {
+		if inTriangle(x, y, tipX, tipY, leftX, leftY, rightX, rightY) {
+			return colorCometh
+		}
+		return 0
+	}, img)
+}
+
+// each samples every pixel centre of r and paints the colour shade returns; zero leaves the pixel alone
+func each(r image.Rectangle, shade func(x, y float64) uint8, img *image.Paletted) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if c := shade(float64(x)+0.5, float64(y)+0.5); c != 0 {
+				img.SetColorIndex(x, y, c)
+			}
+		}
+	}
+}
+
+func fill(img *image.Paletted, r image.Rectangle, c uint8) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetColorIndex(x, y, c)
+		}
+	}
+}
+
+func outline(img *image.Paletted, r image.Rectangle, width int, c uint8) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if x-r.Min.X < width || r.Max.X-1-x < width || y-r.Min.Y < width || r.Max.Y-1-y < width {
+				img.SetColorIndex(x, y, c)
+			}
+		}
+	}
+}
+
+func dist2(x, y, cx, cy float64) float64 {
+	return (x-cx)*(x-cx) + (y-cy)*(y-cy)
+}
+
+func inTriangle(px, py, ax, ay, bx, by, cx, cy float64) bool {
+	side := func(x1, y1, x2, y2 float64) float64 {
+		return (px-x2)*(y1-y2) - (x1-x2)*(py-y2)
+	}
+	d1, d2, d3 := side(ax, ay, bx, by), side(bx, by, cx, cy), side(cx, cy, ax, ay)
+	negative := d1 < 0 || d2 < 0 || d3 < 0
+	positive := d1 > 0 || d2 > 0 || d3 > 0
+	return !(negative && positive)
+}
@@ -0,0 +1,167 @@
+package runstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+)
+
+// File names used inside a run directory
+const (
+	JournalFile = "journal.jsonl"
+	ReportFile  = "report.json"
+	GoalFile    = "goal.json"   // goal map fetched when the run ended, usable with reconcile --goal-file
+	FinalFile   = "final.json"  // live megaverse in goal map form, fetched when the run ended
+	ConfigFile  = "config.json" // effective configuration with secrets redacted
+	LogFile     = "run.log"     // log output of the invocation that recorded the run
+)
+
+// Store lays out the artifacts of each run under <root>/<run-id>/
+type Store struct {
+	root string
+}
+
+// NewStore returns a store rooted at root; directories are created on first write
+func NewStore(root string) *Store {
+	return &Store{root: root}
+}
+
+// Dir returns the directory holding the artifacts of runID
+func (s *Store) Dir(runID string) string {
+	return filepath.Join(s.root, runID)
+}
+
+// Path returns the path of the named artifact of runID
+func (s *Store) Path(runID, name string) string {
+	return filepath.Join(s.Dir(runID), name)
+}
+
+// Latest returns the ID of the most recent run. Run IDs start with a UTC timestamp, so the
+// greatest directory name is the newest run.
+func (s *Store) Latest() (string, error) {
+	dirs, err := os.ReadDir(s.root)
+	if err != nil {
+		return "", fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	latest := ""
+	for _, dir := range dirs {
+		if dir.IsDir() && dir.Name() > latest {
+			latest = dir.Name()
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no runs recorded in %s", s.root)
+	}
+	return latest, nil
+}
+
+// Resolve returns runID, or the most recent run when runID is "latest"
+func (s *Store) Resolve(runID string) (string, error) {
+	if runID == "latest" {
+		return s.Latest()
+	}
+	if _, err := os.Stat(s.Dir(runID)); err != nil {
+		return "", fmt.Errorf("run %s not found in %s", runID, s.root)
+	}
+	return runID, nil
+}
+
+// OpenJournal opens the journal of runID for appending, creating the run directory if needed
+func (s *Store) OpenJournal(runID string) (*Journal, error) {
+	if err := os.MkdirAll(s.Dir(runID), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+
+	file, err := os.OpenFile(s.Path(runID, JournalFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	return &Journal{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Create creates, or truncates, the named artifact of runID for writing
+func (s *Store) Create(runID, name string) (*os.File, error) {
+	if err := os.MkdirAll(s.Dir(runID), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	file, err := os.Create(s.Path(runID, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	return file, nil
+}
+
+// ReadJournal returns the entries recorded for runID in the order they were written
+func (s *Store) ReadJournal(runID string) ([]domain.JournalEntry, error) {
+	file, err := os.Open(s.Path(runID, JournalFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []domain.JournalEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry domain.JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("journal line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	return entries, nil
+}
+
+// WriteJSON stores v as the named artifact of runID, replacing any previous version atomically
+func (s *Store) WriteJSON(runID, name string, v any) error {
+	if err := os.MkdirAll(s.Dir(runID), 0o755); err != nil {
+		return fmt.Errorf("failed to create run directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+
+	path := s.Path(runID, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Journal appends entries to a run's journal file as JSON lines. It is safe for concurrent use.
+type Journal struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// Append writes entry as one line
+func (j *Journal) Append(entry domain.JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.encoder.Encode(entry)
+}
+
+// Close flushes and closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
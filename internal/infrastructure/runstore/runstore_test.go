@@ -0,0 +1,44 @@
+package runstore
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+)
+
+func TestJournalRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	journal, err := store.OpenJournal("run-1")
+	require.NoError(t, err)
+
+	pos := entities.Position{Row: 2, Column: 3}
+	require.NoError(t, journal.Append(domain.JournalEntry{RunID: "run-1", Event: domain.JournalRunStarted, Width: 11, Height: 11}))
+	require.NoError(t, journal.Append(domain.JournalEntry{RunID: "run-1", OperationID: "op-1", Event: domain.JournalObjectApplied, Position: &pos, Object: "POLYANET"}))
+	require.NoError(t, journal.Close())
+
+	entries, err := store.ReadJournal("run-1")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, 11, entries[0].Width)
+	require.Equal(t, "op-1", entries[1].OperationID)
+	require.Equal(t, pos, *entries[1].Position)
+}
+
+func TestWriteJSON(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	require.NoError(t, store.WriteJSON("run-1", ReportFile, map[string]int{"applied": 3}))
+
+	data, err := os.ReadFile(store.Path("run-1", ReportFile))
+	require.NoError(t, err)
+
+	var decoded map[string]int
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, 3, decoded["applied"])
+}
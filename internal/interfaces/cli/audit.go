@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/pkg/audit"
+)
+
+// NewAuditCommand returns the command group for inspecting the audit log of mutating requests.
+func NewAuditCommand(deps *Dependencies) *cobra.Command {
+	var path string
+
+	cmd := &cobra.Command{
+		Use:         "audit",
+		Short:       "Inspect the tamper-evident log of POST and DELETE requests",
+		Annotations: map[string]string{offlineAnnotation: "true"},
+	}
+	cmd.PersistentFlags().StringVar(&path, "file", "", "Audit log to read (defaults to audit.path from the configuration)")
+
+	auditPath := func() (string, error) {
+		if path != "" {
+			return path, nil
+		}
+		if deps.Config != nil && deps.Config.Audit.Path != "" {
+			return deps.Config.Audit.Path, nil
+		}
+		return "", fmt.Errorf("no audit log configured; set audit.path or pass --file")
+	}
+
+	verify := &cobra.Command{
+		Use:   "verify",
+		Short: "Check that no audit entry was edited, removed, or reordered",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := auditPath()
+			if err != nil {
+				return err
+			}
+
+			verified, err := audit.Verify(file)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %d entries verified, chain intact\n", file, verified)
+			return nil
+		},
+	}
+
+	var cell string
+	show := &cobra.Command{
+		Use:   "show",
+		Short: "Show the audited requests that touched one cell",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pos, err := parseCell(cell)
+			if err != nil {
+				return err
+			}
+			file, err := auditPath()
+			if err != nil {
+				return err
+			}
+
+			entries, err := audit.Read(file)
+			if err != nil {
+				return fmt.Errorf("failed to read audit log: %w", err)
+			}
+
+			var history []audit.Entry
+			for _, entry := range entries {
+				if at, ok := entryCell(entry); ok && at == pos {
+					history = append(history, entry)
+				}
+			}
+			if len(history) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No audited requests for cell %d,%d\n", pos.Row, pos.Column)
+				return nil
+			}
+
+			out := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(out, "SEQ\tTIME\tRUN\tMETHOD\tENDPOINT\tATTEMPT\tSTATUS\tBODY")
+			for _, entry := range history {
+				status := strconv.Itoa(entry.Status)
+				if entry.Status == 0 {
+					status = "error: " + entry.Error
+				}
+				fmt.Fprintf(out, "%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+					entry.Seq, entry.Time.Local().Format(time.RFC3339), entry.RunID, entry.Method, entry.Endpoint, entry.Attempt, status, entry.Body)
+			}
+			return out.Flush()
+		},
+	}
+	show.Flags().StringVar(&cell, "cell", "", "Cell to show as row,column (for example 2,3)")
+	_ = show.MarkFlagRequired("cell")
+
+	cmd.AddCommand(verify, show)
+	return cmd
+}
+
+// parseCell parses a "row,column" flag value
+func parseCell(value string) (entities.Position, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return entities.Position{}, fmt.Errorf("cell must be row,column; got %q", value)
+	}
+	row, rowErr := strconv.Atoi(strings.TrimSpace(parts[0]))
+	col, colErr := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if rowErr != nil || colErr != nil || row < 0 || col < 0 {
+		return entities.Position{}, fmt.Errorf("cell must be two non-negative integers; got %q", value)
+	}
+	return entities.Position{Row: row, Column: col}, nil
+}
+
+// entryCell returns the cell an audited request targeted. Entries written before cells were
+// recorded fall back to the row and column of the default request body.
+func entryCell(entry audit.Entry) (entities.Position, bool) {
+	if entry.Cell != nil {
+		return entities.Position{Row: entry.Cell.Row, Column: entry.Cell.Column}, true
+	}
+	var body struct {
+		Row    *int `json:"row"`
+		Column *int `json:"column"`
+	}
+	if len(entry.Body) == 0 || json.Unmarshal(entry.Body, &body) != nil || body.Row == nil || body.Column == nil {
+		return entities.Position{}, false
+	}
+	return entities.Position{Row: *body.Row, Column: *body.Column}, true
+}
@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/bundle"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/runstore"
+	"github.com/crossmint/megaverse-challenge/pkg/audit"
+)
+
+// bundledArtifacts are the run directory files copied into a bundle, in archive order
+var bundledArtifacts = []string{
+	runstore.ReportFile,
+	runstore.JournalFile,
+	runstore.GoalFile,
+	runstore.FinalFile,
+	runstore.ConfigFile,
+	runstore.LogFile,
+}
+
+// NewBundleCommand returns the command that packs a recorded run into one shareable archive.
+func NewBundleCommand(deps *Dependencies) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "bundle <run-id|latest>",
+		Short: "Pack a recorded run into a tar.gz archive for sharing",
+		Long: "Bundle the execution report, journal, goal and final grid snapshots, redacted configuration, logs, " +
+			"and the run's audit log entries into one archive. Artifacts the run did not record are listed as missing.",
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{offlineAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := runStore(deps)
+			if err != nil {
+				return err
+			}
+			runID, err := store.Resolve(args[0])
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				output = "megaverse-" + runID + ".tar.gz"
+			}
+
+			file, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			files, err := writeBundle(file, deps, store, runID)
+			if err != nil {
+				file.Close()
+				os.Remove(output)
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Bundled %d files of run %s into %s\n", files, runID, output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Archive to write (default megaverse-<run-id>.tar.gz)")
+
+	cmd.AddCommand(newBundleInspectCommand())
+	return cmd
+}
+
+// writeBundle archives the artifacts of runID and its audit log entries to w and returns how many
+// files were included
+func writeBundle(w io.Writer, deps *Dependencies, store *runstore.Store, runID string) (int, error) {
+	archive := bundle.NewWriter(w, runID)
+	files := 0
+
+	for _, name := range bundledArtifacts {
+		data, err := os.ReadFile(store.Path(runID, name))
+		if errors.Is(err, os.ErrNotExist) {
+			archive.Missing(name)
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if err := archive.Add(name, data); err != nil {
+			return 0, err
+		}
+		files++
+	}
+
+	entries, err := runAuditEntries(deps, runID)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		archive.Missing(bundle.AuditFile)
+	} else {
+		var data bytes.Buffer
+		encoder := json.NewEncoder(&data)
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				return 0, fmt.Errorf("failed to encode audit entry: %w", err)
+			}
+		}
+		if err := archive.Add(bundle.AuditFile, data.Bytes()); err != nil {
+			return 0, err
+		}
+		files++
+	}
+
+	if err := archive.Close(); err != nil {
+		return 0, err
+	}
+	return files, nil
+}
+
+// runAuditEntries returns the entries of the configured audit log that belong to runID
+func runAuditEntries(deps *Dependencies, runID string) ([]audit.Entry, error) {
+	if deps.Config == nil || deps.Config.Audit.Path == "" {
+		return nil, nil
+	}
+
+	entries, err := audit.Read(deps.Config.Audit.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	var slice []audit.Entry
+	for _, entry := range entries {
+		if entry.RunID == runID {
+			slice = append(slice, entry)
+		}
+	}
+	return slice, nil
+}
+
+func newBundleInspectCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "inspect <archive>",
+		Short: "Summarise a run bundle without contacting the API",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			b, err := bundle.Read(file)
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+
+			printBundleSummary(cmd.OutOrStdout(), b, bundle.Summarize(b))
+			return nil
+		},
+	}
+}
+
+func printBundleSummary(w io.Writer, b *bundle.Bundle, summary bundle.Summary) {
+	out := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	defer out.Flush()
+
+	fmt.Fprintf(out, "Run:\t%s\n", b.Manifest.RunID)
+	fmt.Fprintf(out, "Bundled:\t%s\n", b.Manifest.CreatedAt.Local().Format(time.RFC3339))
+	fmt.Fprintf(out, "Files:\t%s (checksums verified)\n", strings.Join(b.Names(), ", "))
+	if len(b.Manifest.Missing) > 0 {
+		fmt.Fprintf(out, "Missing:\t%s\n", strings.Join(b.Manifest.Missing, ", "))
+	}
+
+	if report := summary.Report; report != nil {
+		fmt.Fprintf(out, "Report:\t%s: planned %d, applied %d, failed %d, skipped %d in %s\n",
+			report.Name, report.Planned, report.Applied, report.Failed, report.Skipped, report.Duration.Round(time.Millisecond))
+	}
+	if journal := summary.Journal; journal != nil {
+		fmt.Fprintf(out, "Journal:\t%d entries (%s)\n", journal.Entries, countList(journal.Events))
+	}
+	if goal := summary.Goal; goal != nil {
+		fmt.Fprintf(out, "Goal:\t%dx%d, %d objects\n", goal.Width, goal.Height, goal.Objects)
+	}
+	if final := summary.Final; final != nil {
+		state := ""
+		switch {
+		case summary.Mismatches == 0:
+			state = ", matches the goal"
+		case summary.Mismatches > 0:
+			state = fmt.Sprintf(", %d cells differ from the goal", summary.Mismatches)
+		}
+		fmt.Fprintf(out, "Final:\t%dx%d, %d objects%s\n", final.Width, final.Height, final.Objects, state)
+	}
+	if requests := summary.Audit; requests != nil {
+		fmt.Fprintf(out, "Audit:\t%d requests (%s), %d failed\n", requests.Requests, countList(requests.Methods), requests.Failures)
+	}
+	if logs := summary.Logs; logs != nil {
+		fmt.Fprintf(out, "Logs:\t%d lines, %d warnings, %d errors\n", logs.Lines, logs.Warnings, logs.Errors)
+	}
+	if summary.Settings > 0 {
+		fmt.Fprintf(out, "Config:\t%d settings (secrets redacted)\n", summary.Settings)
+	}
+}
+
+// countList renders counts as "a 1, b 2" in key order
+func countList(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s %d", key, counts[key]))
+	}
+	return strings.Join(parts, ", ")
+}
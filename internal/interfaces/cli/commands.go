@@ -3,13 +3,21 @@ package cli
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/crossmint/megaverse-challenge/internal/application"
 	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/api"
 	cfgpkg "github.com/crossmint/megaverse-challenge/internal/infrastructure/config"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/events"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/runstore"
+	"github.com/crossmint/megaverse-challenge/pkg/correlation"
+	"github.com/crossmint/megaverse-challenge/pkg/metrics"
+	"github.com/crossmint/megaverse-challenge/pkg/tracing"
 )
 
 // Dependencies bundles the services required by CLI commands.
@@ -18,6 +26,59 @@ type Dependencies struct {
 	ConfigPath string
 	Service    *application.MegaverseService
 	Repository domain.MegaverseRepository
+	Logger     *slog.Logger
+	Metrics    *metrics.Registry
+	Tracer     *tracing.Tracer
+
+	// RunID identifies this invocation in logs, run artifacts and X-Request-ID headers.
+	// It is minted before Setup runs when left empty.
+	RunID string
+
+	// Setup loads configuration and wires the services above. It runs after flags are parsed,
+	// so flag values such as ConfigPath and TraceFile are already set.
+	Setup func(deps *Dependencies) error
+
+	// MetricsAddr serves /metrics while a command runs when set
+	MetricsAddr string
+	// MetricsTextfile receives a metrics snapshot when a command ends when set
+	MetricsTextfile string
+	// TraceFile receives OTLP JSON spans for the run when set
+	TraceFile string
+	// DebugHTTP logs every HTTP request and response in full, with the candidate ID redacted
+	DebugHTTP bool
+	// ReportFile receives a self-contained HTML report after phase and reconcile runs when set
+	ReportFile string
+	// Requests collects every HTTP attempt for the HTML report; nil when no report was requested
+	Requests *api.RequestLog
+	// EventsFormat selects the machine-readable event stream ("ndjson"); empty disables it
+	EventsFormat string
+	// EventsFile receives the event stream instead of stdout when set
+	EventsFile string
+	// Offline reads the goal map from the goal cache only, never from the API
+	Offline bool
+
+	runs     *runstore.Store
+	captured *capturingJournal
+	events   *events.Emitter
+	logs     *logTee
+	cleanup  []func()
+
+	circuitMu sync.Mutex
+	circuit   []application.CircuitChange
+}
+
+// Close releases resources started for the command, such as the metrics server, and writes end-of-run
+// artifacts. It runs whether or not the command succeeded.
+func (d *Dependencies) Close() {
+	for i := len(d.cleanup) - 1; i >= 0; i-- {
+		d.cleanup[i]()
+	}
+	d.cleanup = nil
+}
+
+// OnClose registers fn to run when the command ends; functions run in reverse registration order
+func (d *Dependencies) OnClose(fn func()) {
+	d.cleanup = append(d.cleanup, fn)
 }
 
 // NewRootCommand creates the root cobra command and registers all subcommands.
@@ -27,7 +88,15 @@ func NewRootCommand(deps *Dependencies) *cobra.Command {
 		Short: "Command line tools for mastering the Crossmint megaverse",
 		Long:  "Megaverse CLI allows you to initialise, render and validate Crossmint megaverses programmatically.",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if cmd.Name() == "init" {
+			if deps.RunID == "" {
+				deps.RunID = correlation.NewRunID()
+			}
+			if deps.Setup != nil {
+				if err := deps.Setup(deps); err != nil {
+					return err
+				}
+			}
+			if cmd.Name() == "init" || isOffline(cmd) {
 				return nil
 			}
 			if deps.Config == nil {
@@ -36,20 +105,43 @@ func NewRootCommand(deps *Dependencies) *cobra.Command {
 			if deps.Config.API.CandidateID == "" {
 				return fmt.Errorf("candidate ID missing; run 'megaverse init --candidate <id>'")
 			}
-			return nil
+			return startMetrics(deps)
 		},
 	}
 
 	rootCmd.PersistentFlags().StringVar(&deps.ConfigPath, "config", deps.ConfigPath, "Path to configuration file")
+	rootCmd.PersistentFlags().StringVar(&deps.MetricsAddr, "metrics-addr", "", "Serve Prometheus metrics at http://<addr>/metrics while the command runs")
+	rootCmd.PersistentFlags().StringVar(&deps.MetricsTextfile, "metrics-textfile", "", "Write a Prometheus textfile snapshot of the metrics when the command ends")
+	rootCmd.PersistentFlags().StringVar(&deps.TraceFile, "trace-file", "", "Export tracing spans as OTLP JSON lines to this file")
+	rootCmd.PersistentFlags().BoolVar(&deps.DebugHTTP, "debug-http", false, "Log method, URL, headers, body, status, and timing of every HTTP attempt (candidate ID redacted)")
+	rootCmd.PersistentFlags().StringVar(&deps.ReportFile, "report", "", "Write a self-contained HTML report of the run to this file")
+	rootCmd.PersistentFlags().BoolVar(&deps.Offline, "offline", false, "Plan from the cached goal map without fetching it from the API")
 
 	rootCmd.AddCommand(NewInitCommand(deps))
 	rootCmd.AddCommand(NewPhase1Command(deps))
 	rootCmd.AddCommand(NewPhase2Command(deps))
 	rootCmd.AddCommand(NewStatusCommand(deps))
+	rootCmd.AddCommand(NewReconcileCommand(deps))
+	rootCmd.AddCommand(NewAuditCommand(deps))
+	rootCmd.AddCommand(NewReplayCommand(deps))
+	rootCmd.AddCommand(NewBundleCommand(deps))
 
 	return rootCmd
 }
 
+// offlineAnnotation marks commands, and their subcommands, that work on local files only and
+// therefore do not need a candidate ID
+const offlineAnnotation = "offline"
+
+func isOffline(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[offlineAnnotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
 // withTimeout returns a context with timeout suitable for API calls.
 func withTimeout(parent context.Context, deps *Dependencies) (context.Context, context.CancelFunc) {
 	timeout := 2 * time.Minute
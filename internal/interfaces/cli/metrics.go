@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// startMetrics starts the metrics endpoint and registers the end-of-run snapshot, as requested by flags
+func startMetrics(deps *Dependencies) error {
+	logger := deps.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if deps.MetricsTextfile != "" {
+		path := deps.MetricsTextfile
+		deps.OnClose(func() {
+			if err := deps.Metrics.WriteTextfile(path); err != nil {
+				logger.Error("failed to write metrics snapshot", "path", path, "error", err)
+				return
+			}
+			logger.Info("wrote metrics snapshot", "path", path)
+		})
+	}
+
+	if deps.MetricsAddr == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", deps.MetricsAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on metrics address: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", deps.Metrics.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics server stopped", "error", err)
+		}
+	}()
+	logger.Info("serving metrics", "url", fmt.Sprintf("http://%s/metrics", listener.Addr()))
+
+	deps.OnClose(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	})
+	return nil
+}
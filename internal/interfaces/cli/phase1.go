@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -11,6 +10,8 @@ import (
 
 // NewPhase1Command returns the command that executes Phase 1 of the challenge.
 func NewPhase1Command(deps *Dependencies) *cobra.Command {
+	var interactive bool
+
 	cmd := &cobra.Command{
 		Use:   "phase1",
 		Short: "Create the Phase 1 POLYanet cross",
@@ -19,11 +20,18 @@ func NewPhase1Command(deps *Dependencies) *cobra.Command {
 				return fmt.Errorf("service dependency not initialised")
 			}
 
-			ctx, cancel := withTimeout(context.Background(), deps)
+			configureApproval(cmd, deps, interactive)
+			if err := beginRun(cmd, deps); err != nil {
+				return err
+			}
+
+			ctx, cancel := withTimeout(deps.Context(), deps)
 			defer cancel()
 
 			strategy := strategies.NewCrossPatternStrategy()
-			if err := deps.Service.ExecuteStrategy(ctx, strategy); err != nil {
+			report, err := deps.Service.ExecuteStrategy(ctx, strategy)
+			finishRun(ctx, cmd.OutOrStdout(), deps, report, desiredState(deps.Repository, ""))
+			if err != nil {
 				return fmt.Errorf("failed to execute Phase 1 strategy: %w", err)
 			}
 
@@ -31,5 +39,8 @@ func NewPhase1Command(deps *Dependencies) *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&interactive, "interactive", false, "Ask for approval before each change")
+	addEventFlags(cmd, deps)
 	return cmd
 }
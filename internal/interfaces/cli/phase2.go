@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -11,6 +10,8 @@ import (
 
 // NewPhase2Command returns the command that executes Phase 2 of the challenge.
 func NewPhase2Command(deps *Dependencies) *cobra.Command {
+	var interactive bool
+
 	cmd := &cobra.Command{
 		Use:   "phase2",
 		Short: "Render the Phase 2 megaverse logo",
@@ -19,11 +20,18 @@ func NewPhase2Command(deps *Dependencies) *cobra.Command {
 				return fmt.Errorf("dependencies not initialised for Phase 2")
 			}
 
-			ctx, cancel := withTimeout(context.Background(), deps)
+			configureApproval(cmd, deps, interactive)
+			if err := beginRun(cmd, deps); err != nil {
+				return err
+			}
+
+			ctx, cancel := withTimeout(deps.Context(), deps)
 			defer cancel()
 
-			strategy := strategies.NewLogoPatternStrategy(deps.Repository)
-			if err := deps.Service.ExecuteStrategy(ctx, strategy); err != nil {
+			strategy := strategies.NewLogoPatternStrategy(deps.Repository, deps.Logger)
+			report, err := deps.Service.ExecuteStrategy(ctx, strategy)
+			finishRun(ctx, cmd.OutOrStdout(), deps, report, desiredState(deps.Repository, ""))
+			if err != nil {
 				return fmt.Errorf("failed to execute Phase 2 strategy: %w", err)
 			}
 
@@ -31,5 +39,8 @@ func NewPhase2Command(deps *Dependencies) *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&interactive, "interactive", false, "Ask for approval before each change")
+	addEventFlags(cmd, deps)
 	return cmd
 }
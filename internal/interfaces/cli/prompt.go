@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/crossmint/megaverse-challenge/internal/application"
+	"github.com/crossmint/megaverse-challenge/internal/domain/operations"
+)
+
+// promptApprover asks the operator about each change on the terminal
+type promptApprover struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPromptApprover(in io.Reader, out io.Writer) *promptApprover {
+	return &promptApprover{in: bufio.NewReader(in), out: out}
+}
+
+// Approve shows the cell with its current and desired objects and reads the operator's answer.
+// End of input is treated as quit so a closed stdin never applies anything unattended.
+func (p *promptApprover) Approve(ctx context.Context, op operations.Operation) (application.Answer, error) {
+	fmt.Fprintf(p.out, "\n%s at (%d, %d)\n", strings.ToUpper(op.Kind.String()), op.Position.Row, op.Position.Column)
+	fmt.Fprintf(p.out, "  current: %s\n", operations.Describe(op.Previous))
+	fmt.Fprintf(p.out, "  desired: %s\n", operations.Describe(op.Object))
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return application.AnswerQuit, err
+		}
+
+		fmt.Fprint(p.out, "Apply? [y]es / [n]o / [a]ll / [s]kip-type / [q]uit: ")
+
+		line, err := p.in.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return application.AnswerQuit, fmt.Errorf("failed to read answer: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			return application.AnswerYes, nil
+		case "n", "no":
+			return application.AnswerNo, nil
+		case "a", "all":
+			return application.AnswerAll, nil
+		case "s", "skip-type", "skip":
+			return application.AnswerSkipType, nil
+		case "q", "quit":
+			return application.AnswerQuit, nil
+		}
+
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(p.out)
+			return application.AnswerQuit, nil
+		}
+		fmt.Fprintln(p.out, "Please answer y, n, a, s, or q.")
+	}
+}
+
+// configureApproval enables interactive approval on the service when requested
+func configureApproval(cmd *cobra.Command, deps *Dependencies, interactive bool) {
+	if !interactive {
+		return
+	}
+	deps.Service.WithApprover(newPromptApprover(cmd.InOrStdin(), cmd.ErrOrStderr()))
+}
+
+// printRunSummary writes the outcome counters and any interactive decisions of a run
+func printRunSummary(w io.Writer, report *application.RunReport) {
+	if report == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "%s: %d planned, %d applied, %d failed, %d skipped in %s\n",
+		report.Name, report.Planned, report.Applied, report.Failed, report.Skipped, report.Duration().Round(time.Millisecond))
+
+	if len(report.CircuitChanges) > 0 {
+		changes := make([]string, 0, len(report.CircuitChanges))
+		for _, change := range report.CircuitChanges {
+			changes = append(changes, fmt.Sprintf("%s at %s", change.To, change.At.Local().Format(time.TimeOnly)))
+		}
+		fmt.Fprintf(w, "Circuit breaker: %s\n", strings.Join(changes, ", "))
+	}
+
+	if len(report.Decisions) == 0 {
+		return
+	}
+
+	counts := make(map[application.Answer]int)
+	for _, decision := range report.Decisions {
+		counts[decision.Answer]++
+	}
+	fmt.Fprintf(w, "Decisions: %d yes, %d no, %d all, %d skip-type, %d quit\n",
+		counts[application.AnswerYes], counts[application.AnswerNo], counts[application.AnswerAll],
+		counts[application.AnswerSkipType], counts[application.AnswerQuit])
+}
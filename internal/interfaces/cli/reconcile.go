@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/crossmint/megaverse-challenge/internal/application"
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/domain/entities"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/goalfile"
+)
+
+// NewReconcileCommand returns the command that converges the live megaverse on a desired state.
+func NewReconcileCommand(deps *Dependencies) *cobra.Command {
+	var goalFile string
+	var watch bool
+	var interval time.Duration
+	var interactive bool
+
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Correct drift between the live megaverse and the goal",
+		Long: "Compare the live map with the desired state (the API goal or a local goal file) and create, delete, or replace cells until they match. " +
+			"With --watch the command keeps running and reconciles on a timer and whenever the goal file changes.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if deps.Service == nil || deps.Repository == nil {
+				return fmt.Errorf("dependencies not initialised for reconcile")
+			}
+
+			configureApproval(cmd, deps, interactive)
+			desired := desiredState(deps.Repository, goalFile)
+			if err := beginRun(cmd, deps); err != nil {
+				return err
+			}
+
+			if !watch {
+				ctx, cancel := withTimeout(deps.Context(), deps)
+				defer cancel()
+
+				goal, err := desired(ctx)
+				if err != nil {
+					return err
+				}
+
+				report, err := deps.Service.Reconcile(ctx, goal)
+				finishRun(ctx, cmd.OutOrStdout(), deps, report, desired)
+				if err != nil {
+					return fmt.Errorf("failed to reconcile: %w", err)
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Reconciled %d of %d drifted cells\n", report.Applied, len(report.Operations))
+				return nil
+			}
+
+			ctx, stop := signal.NotifyContext(deps.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			var trigger <-chan struct{}
+			if goalFile != "" {
+				changes, err := goalfile.Watch(ctx, goalFile)
+				if err != nil {
+					return err
+				}
+				trigger = changes
+			}
+
+			controller := application.NewReconcileController(deps.Service, desired, interval, trigger)
+			if deps.Config != nil {
+				controller.PassTimeout = deps.Config.Execution.Timeout
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Watching megaverse every %s (Ctrl+C to stop)\n", interval)
+			return controller.Run(ctx)
+		},
+	}
+
+	cmd.Flags().StringVar(&goalFile, "goal-file", "", "Reconcile against a local goal JSON file instead of the API goal")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Keep running and reconcile continuously")
+	cmd.Flags().DurationVar(&interval, "interval", time.Minute, "Time between reconciliation passes in watch mode")
+	cmd.Flags().BoolVar(&interactive, "interactive", false, "Ask for approval before each change")
+
+	return cmd
+}
+
+// desiredState loads the goal from goalFile when set, otherwise from the API
+func desiredState(repository domain.MegaverseRepository, goalFile string) application.DesiredState {
+	return func(ctx context.Context) (*entities.Megaverse, error) {
+		var goal *domain.GoalMap
+		var err error
+		if goalFile != "" {
+			goal, err = goalfile.Load(goalFile)
+		} else {
+			goal, err = repository.GetGoalMap(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return goal.ToMegaverse()
+	}
+}
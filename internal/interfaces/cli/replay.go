@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/replay"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/runstore"
+)
+
+// NewReplayCommand returns the command that renders a recorded run as an animation.
+func NewReplayCommand(deps *Dependencies) *cobra.Command {
+	var output string
+	var opts replay.Options
+
+	cmd := &cobra.Command{
+		Use:         "replay <run-id|latest>",
+		Short:       "Render a recorded run frame by frame as an animated GIF",
+		Long:        "Replay the run journal in the order objects were actually created. Cells whose creation failed are framed in red.",
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{offlineAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := runStore(deps)
+			if err != nil {
+				return err
+			}
+			runID, err := store.Resolve(args[0])
+			if err != nil {
+				return err
+			}
+
+			entries, err := store.ReadJournal(runID)
+			if err != nil {
+				return err
+			}
+
+			file, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			if err := replay.Render(file, entries, opts); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d frames of run %s to %s\n", replay.Frames(entries), runID, output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "gif", "", "File to write the animated GIF to")
+	cmd.Flags().IntVar(&opts.CellSize, "cell-size", 16, "Size of one grid cell in pixels")
+	cmd.Flags().DurationVar(&opts.FrameDelay, "frame-delay", 100*time.Millisecond, "Time each change stays on screen")
+	cmd.Flags().DurationVar(&opts.FinalDelay, "final-delay", 3*time.Second, "Time the finished megaverse stays on screen")
+	_ = cmd.MarkFlagRequired("gif")
+
+	return cmd
+}
+
+// runStore returns the store holding recorded runs
+func runStore(deps *Dependencies) (*runstore.Store, error) {
+	if deps.Config == nil || deps.Config.Execution.RunsDir == "" {
+		return nil, fmt.Errorf("no runs directory configured; set execution.runs_dir")
+	}
+	return runstore.NewStore(deps.Config.Execution.RunsDir), nil
+}
@@ -0,0 +1,340 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/crossmint/megaverse-challenge/internal/application"
+	"github.com/crossmint/megaverse-challenge/internal/domain"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/api"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/events"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/htmlreport"
+	"github.com/crossmint/megaverse-challenge/internal/infrastructure/runstore"
+	"github.com/crossmint/megaverse-challenge/pkg/breaker"
+	"github.com/crossmint/megaverse-challenge/pkg/correlation"
+)
+
+// Context returns the base context for a command, carrying the invocation's run ID
+func (d *Dependencies) Context() context.Context {
+	return correlation.WithRunID(context.Background(), d.RunID)
+}
+
+func (d *Dependencies) logger() *slog.Logger {
+	if d.Logger != nil {
+		return d.Logger
+	}
+	return slog.Default()
+}
+
+// LogWriter returns the destination for log output: stderr, plus the run's log file once a run
+// has begun. Setup builds the logger on it so runs can record their logs.
+func (d *Dependencies) LogWriter() io.Writer {
+	if d.logs == nil {
+		d.logs = &logTee{out: os.Stderr}
+	}
+	return d.logs
+}
+
+// logTee copies log output to a run's log file while one is attached
+type logTee struct {
+	mu   sync.Mutex
+	out  io.Writer
+	file io.Writer
+}
+
+func (t *logTee) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.file != nil {
+		// A log file that stops accepting writes must not silence the terminal.
+		_, _ = t.file.Write(p)
+	}
+	return t.out.Write(p)
+}
+
+func (t *logTee) attach(file io.Writer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.file = file
+}
+
+// capturingJournal keeps the entries of this invocation in memory for end-of-run reports
+type capturingJournal struct {
+	mu      sync.Mutex
+	entries []domain.JournalEntry
+}
+
+func (j *capturingJournal) Append(entry domain.JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, entry)
+	return nil
+}
+
+func (j *capturingJournal) Entries() []domain.JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]domain.JournalEntry(nil), j.entries...)
+}
+
+// fanoutJournal forwards every entry to each journal, returning the first error
+type fanoutJournal []domain.Journal
+
+func (f fanoutJournal) Append(entry domain.JournalEntry) error {
+	var first error
+	for _, journal := range f {
+		if err := journal.Append(entry); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// ObserveRequest forwards a finished HTTP attempt to the collectors this run asked for.
+// It is the client's request observer and is called from concurrent workers.
+func (d *Dependencies) ObserveRequest(record api.RequestRecord) {
+	if d.Requests != nil {
+		d.Requests.Observe(record)
+	}
+	if d.events != nil {
+		d.events.ObserveRequest(record)
+	}
+}
+
+// ObserveCircuit records a circuit breaker state change so it can be added to the run report.
+// It is the client's circuit breaker callback.
+func (d *Dependencies) ObserveCircuit(t breaker.Transition) {
+	d.circuitMu.Lock()
+	defer d.circuitMu.Unlock()
+	d.circuit = append(d.circuit, application.CircuitChange{From: t.From.String(), To: t.To.String(), At: t.At})
+}
+
+// addCircuitChanges copies the circuit breaker state changes seen during the run into report
+func (d *Dependencies) addCircuitChanges(report *application.RunReport) {
+	d.circuitMu.Lock()
+	defer d.circuitMu.Unlock()
+	for _, change := range d.circuit {
+		if !change.At.Before(report.StartedAt) {
+			report.RecordCircuitChange(change)
+		}
+	}
+}
+
+// beginRun attaches the run's journals to the service: the journal file under the configured runs
+// directory, an in-memory copy when an HTML report was requested, and the event stream when one
+// was requested. The run directory also receives the redacted configuration and, from here on,
+// the log output. It must run before the command sends any request.
+func beginRun(cmd *cobra.Command, deps *Dependencies) error {
+	if deps.Service == nil {
+		return nil
+	}
+
+	var journals fanoutJournal
+	if deps.Config != nil && deps.Config.Execution.RunsDir != "" {
+		store := runstore.NewStore(deps.Config.Execution.RunsDir)
+		file, err := store.OpenJournal(deps.RunID)
+		if err != nil {
+			return err
+		}
+		deps.runs = store
+		deps.OnClose(func() {
+			if err := file.Close(); err != nil {
+				deps.logger().Warn("failed to close run journal", "error", err)
+			}
+		})
+		journals = append(journals, file)
+
+		if err := recordRunContext(deps); err != nil {
+			deps.logger().Warn("failed to record run context", "error", err)
+		}
+	}
+
+	if deps.ReportFile != "" {
+		deps.Requests = &api.RequestLog{}
+		deps.captured = &capturingJournal{}
+		journals = append(journals, deps.captured)
+	}
+
+	if err := openEvents(cmd, deps); err != nil {
+		return err
+	}
+	if deps.events != nil {
+		journals = append(journals, deps.events)
+	}
+
+	if len(journals) > 0 {
+		deps.Service.WithJournal(journals)
+	}
+	return nil
+}
+
+// recordRunContext stores the redacted configuration of the run and starts copying logs into its
+// directory, so a bundle of the run can be shared without the machine that produced it
+func recordRunContext(deps *Dependencies) error {
+	if err := deps.runs.WriteJSON(deps.RunID, runstore.ConfigFile, deps.Config.Settings()); err != nil {
+		return err
+	}
+	if deps.logs == nil {
+		return nil
+	}
+
+	file, err := deps.runs.Create(deps.RunID, runstore.LogFile)
+	if err != nil {
+		return err
+	}
+	deps.logs.attach(file)
+	deps.OnClose(func() {
+		deps.logs.attach(nil)
+		_ = file.Close()
+	})
+	return nil
+}
+
+// addEventFlags registers the flags selecting the machine-readable event stream
+func addEventFlags(cmd *cobra.Command, deps *Dependencies) {
+	cmd.Flags().StringVar(&deps.EventsFormat, "events", "", fmt.Sprintf("Emit execution events in this format (ndjson, schema v%d)", events.SchemaVersion))
+	cmd.Flags().StringVar(&deps.EventsFile, "events-file", "", "Write events to this file instead of stdout")
+}
+
+// openEvents starts the event stream requested by flags. Events written to stdout take it over,
+// so the command's human-readable output moves to stderr.
+func openEvents(cmd *cobra.Command, deps *Dependencies) error {
+	switch deps.EventsFormat {
+	case "":
+		return nil
+	case "ndjson":
+	default:
+		return fmt.Errorf("unsupported events format %q: must be ndjson", deps.EventsFormat)
+	}
+
+	if deps.EventsFile == "" || deps.EventsFile == "-" {
+		deps.events = events.NewEmitter(cmd.OutOrStdout())
+		cmd.SetOut(cmd.ErrOrStderr())
+		return nil
+	}
+
+	file, err := os.Create(deps.EventsFile)
+	if err != nil {
+		return fmt.Errorf("failed to create events file: %w", err)
+	}
+	deps.events = events.NewEmitter(file)
+	deps.OnClose(func() {
+		if err := file.Close(); err != nil {
+			deps.logger().Warn("failed to close events file", "error", err)
+		}
+	})
+	return nil
+}
+
+// finishRun prints the run summary, stores the report and snapshots of the goal and final grids
+// next to the run's journal, and writes the HTML report when one was requested. goal supplies the
+// desired grid.
+func finishRun(ctx context.Context, w io.Writer, deps *Dependencies, report *application.RunReport, goal application.DesiredState) {
+	if report != nil {
+		deps.addCircuitChanges(report)
+	}
+	printRunSummary(w, report)
+	if report == nil {
+		return
+	}
+
+	if deps.events != nil {
+		if err := deps.events.Summary(events.Summary{
+			RunID:    report.RunID,
+			Name:     report.Name,
+			Planned:  report.Planned,
+			Applied:  report.Applied,
+			Failed:   report.Failed,
+			Skipped:  report.Skipped,
+			Duration: report.Duration(),
+		}); err != nil {
+			deps.logger().Warn("failed to write run summary event", "error", err)
+		}
+	}
+
+	var goalGrid, finalGrid [][]string
+	if deps.runs != nil || deps.ReportFile != "" {
+		goalGrid, finalGrid = snapshotGrids(ctx, deps, goal)
+	}
+
+	if deps.runs != nil {
+		for name, grid := range map[string][][]string{runstore.GoalFile: goalGrid, runstore.FinalFile: finalGrid} {
+			if grid == nil {
+				continue
+			}
+			if err := deps.runs.WriteJSON(deps.RunID, name, domain.GoalMap{Goal: grid}); err != nil {
+				deps.logger().Warn("failed to write grid snapshot", "file", name, "error", err)
+			}
+		}
+		if err := deps.runs.WriteJSON(deps.RunID, runstore.ReportFile, report); err != nil {
+			deps.logger().Warn("failed to write run report", "error", err)
+		} else {
+			fmt.Fprintf(w, "Run %s recorded in %s\n", deps.RunID, deps.runs.Dir(deps.RunID))
+		}
+	}
+
+	if deps.ReportFile != "" {
+		if err := writeHTMLReport(deps, report, goalGrid, finalGrid); err != nil {
+			deps.logger().Warn("failed to write HTML report", "path", deps.ReportFile, "error", err)
+			return
+		}
+		fmt.Fprintf(w, "Report written to %s\n", deps.ReportFile)
+	}
+}
+
+// snapshotGrids fetches the goal and final grids of the run. Grids that cannot be fetched are left
+// out rather than failing, since they matter most when a run went wrong.
+func snapshotGrids(ctx context.Context, deps *Dependencies, goal application.DesiredState) (goalGrid, finalGrid [][]string) {
+	if goal != nil {
+		desired, err := goal(ctx)
+		if err != nil {
+			deps.logger().Warn("failed to load goal map for run artifacts", "error", err)
+		}
+		goalGrid = domain.Grid(desired)
+	}
+
+	if deps.Repository != nil {
+		current, err := deps.Repository.GetCurrentMap(ctx)
+		if err != nil {
+			deps.logger().Warn("failed to fetch current map for run artifacts", "error", err)
+		}
+		finalGrid = domain.Grid(current)
+	}
+	return goalGrid, finalGrid
+}
+
+// writeHTMLReport renders the HTML report from the run's report, captured journal and requests
+func writeHTMLReport(deps *Dependencies, report *application.RunReport, goalGrid, finalGrid [][]string) error {
+	data := htmlreport.Data{
+		Name:       report.Name,
+		RunID:      report.RunID,
+		StartedAt:  report.StartedAt,
+		FinishedAt: report.FinishedAt,
+		Planned:    report.Planned,
+		Applied:    report.Applied,
+		Failed:     report.Failed,
+		Skipped:    report.Skipped,
+		Goal:       goalGrid,
+		Final:      finalGrid,
+	}
+	if deps.Config != nil {
+		data.Settings = deps.Config.Settings()
+	}
+	if deps.Requests != nil {
+		data.Requests = deps.Requests.Records()
+	}
+
+	var entries []domain.JournalEntry
+	if deps.captured != nil {
+		entries = deps.captured.Entries()
+	}
+	data.Failures, data.Retries = htmlreport.CellStats(entries, data.Requests)
+
+	return htmlreport.Write(deps.ReportFile, data)
+}
@@ -3,6 +3,7 @@ package cli
 import (
     "context"
     "fmt"
+    "strings"
     "time"
 
     "github.com/spf13/cobra"
@@ -19,10 +20,10 @@ func NewStatusCommand(deps *Dependencies) *cobra.Command {
             }
 
             fmt.Fprintf(cmd.OutOrStdout(), "Candidate ID: %s\n", deps.Config.API.CandidateID)
-            fmt.Fprintf(cmd.OutOrStdout(), "API Base URL: %s\n", deps.Config.API.BaseURL)
+            fmt.Fprintf(cmd.OutOrStdout(), "API Base URL: %s\n", strings.Join(deps.Config.API.ResolvedBaseURLs(), ", "))
 
             if deps.Repository != nil {
-                ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+                ctx, cancel := context.WithTimeout(deps.Context(), 30*time.Second)
                 defer cancel()
 
                 goal, err := deps.Repository.GetGoalMap(ctx)
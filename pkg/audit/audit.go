@@ -0,0 +1,218 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// genesisHash is the previous hash of the first entry in a log
+const genesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Cell is a grid position targeted by an audited request
+type Cell struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+}
+
+// Entry is one audited request. Hash covers every other field, including PrevHash, so editing,
+// removing, or reordering entries breaks the chain from that point on.
+type Entry struct {
+	Seq         int64           `json:"seq"`
+	Time        time.Time       `json:"time"`
+	RunID       string          `json:"run_id,omitempty"`
+	OperationID string          `json:"op_id,omitempty"`
+	Method      string          `json:"method"`
+	Endpoint    string          `json:"endpoint"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	Cell        *Cell           `json:"cell,omitempty"` // nil for requests without a single target cell
+	Status      int             `json:"status"`
+	Attempt     int             `json:"attempt"`
+	Error       string          `json:"error,omitempty"`
+	PrevHash    string          `json:"prev_hash"`
+	Hash        string          `json:"hash"`
+}
+
+// computeHash returns the chain hash of e, ignoring its current Hash
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log appends entries to a hash-chained JSON lines file. It is safe for concurrent use
+// within one process.
+type Log struct {
+	mu       sync.Mutex
+	file     *os.File
+	seq      int64
+	lastHash string
+}
+
+// Open opens the log at path for appending, creating it if needed, and continues the existing chain
+func Open(path string) (*Log, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create audit directory: %w", err)
+		}
+	}
+
+	log := &Log{lastHash: genesisHash}
+	entries, err := Read(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if n := len(entries); n > 0 {
+		log.seq = entries[n-1].Seq
+		log.lastHash = entries[n-1].Hash
+	}
+
+	log.file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return log, nil
+}
+
+// Append chains entry onto the log and writes it. Seq, PrevHash and Hash are assigned here;
+// Time defaults to now.
+func (l *Log) Append(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	// Normalise so the hash survives a JSON round trip unchanged
+	entry.Time = entry.Time.UTC().Round(0)
+	if len(entry.Body) > 0 {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, entry.Body); err != nil {
+			return fmt.Errorf("audit body is not valid JSON: %w", err)
+		}
+		entry.Body = compacted.Bytes()
+	}
+
+	entry.Seq = l.seq + 1
+	entry.PrevHash = l.lastHash
+	hash, err := entry.computeHash()
+	if err != nil {
+		return fmt.Errorf("failed to hash audit entry: %w", err)
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+
+	l.seq = entry.Seq
+	l.lastHash = entry.Hash
+	return nil
+}
+
+// Close closes the underlying file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Read returns every entry of the log at path without verifying the chain
+func Read(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	err = scan(file, func(line int, entry Entry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// VerifyError describes the first entry at which the chain no longer holds
+type VerifyError struct {
+	Line   int
+	Seq    int64
+	Reason string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("audit chain broken at line %d (seq %d): %s", e.Line, e.Seq, e.Reason)
+}
+
+// Verify checks every entry of the log at path against the chain and returns how many entries
+// were verified. A broken chain is reported as a *VerifyError.
+func Verify(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	verified := 0
+	prevHash := genesisHash
+	var prevSeq int64
+
+	err = scan(file, func(line int, entry Entry) error {
+		fail := func(format string, args ...any) error {
+			return &VerifyError{Line: line, Seq: entry.Seq, Reason: fmt.Sprintf(format, args...)}
+		}
+
+		if entry.Seq != prevSeq+1 {
+			return fail("expected seq %d", prevSeq+1)
+		}
+		if entry.PrevHash != prevHash {
+			return fail("previous hash does not match the entry before it")
+		}
+		hash, err := entry.computeHash()
+		if err != nil {
+			return fail("%v", err)
+		}
+		if hash != entry.Hash {
+			return fail("entry content does not match its hash")
+		}
+
+		prevSeq, prevHash = entry.Seq, entry.Hash
+		verified++
+		return nil
+	})
+	return verified, err
+}
+
+func scan(r io.Reader, fn func(line int, entry Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return &VerifyError{Line: line, Reason: fmt.Sprintf("malformed entry: %v", err)}
+		}
+		if err := fn(line, entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
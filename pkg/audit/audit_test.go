@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeEntries(t *testing.T, path string, n int) {
+	t.Helper()
+	log, err := Open(path)
+	require.NoError(t, err)
+	for i := 0; i < n; i++ {
+		require.NoError(t, log.Append(Entry{
+			RunID:    "run-1",
+			Method:   "POST",
+			Endpoint: "/polyanets",
+			Body:     []byte(`{"row": 1, "column": 2, "candidateId": "{candidateId}"}`),
+			Status:   200,
+			Attempt:  1,
+		}))
+	}
+	require.NoError(t, log.Close())
+}
+
+func TestChainSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	writeEntries(t, path, 2)
+	writeEntries(t, path, 2)
+
+	verified, err := Verify(path)
+	require.NoError(t, err)
+	require.Equal(t, 4, verified)
+
+	entries, err := Read(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), entries[3].Seq)
+	require.Equal(t, entries[2].Hash, entries[3].PrevHash)
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	writeEntries(t, path, 3)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	edited := append([]string(nil), lines...)
+	edited[1] = strings.Replace(edited[1], `"status":200`, `"status":201`, 1)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(edited, "\n")+"\n"), 0o600))
+
+	_, err = Verify(path)
+	var verifyErr *VerifyError
+	require.True(t, errors.As(err, &verifyErr))
+	require.Equal(t, 2, verifyErr.Line)
+
+	removed := []string{lines[0], lines[2]}
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(removed, "\n")+"\n"), 0o600))
+
+	_, err = Verify(path)
+	require.True(t, errors.As(err, &verifyErr))
+	require.Equal(t, 2, verifyErr.Line)
+	require.Contains(t, verifyErr.Reason, "expected seq 2")
+}
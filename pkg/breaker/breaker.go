@@ -0,0 +1,207 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// State is the position of a circuit breaker
+type State int
+
+const (
+	// Closed lets every call through and counts failures
+	Closed State = iota
+	// Open fails every call fast until the cooldown has passed
+	Open
+	// HalfOpen lets a single probe through to decide whether to close again
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// ErrOpen is returned by Allow while calls are being failed fast
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Config tunes when a breaker opens and how long it stays open
+type Config struct {
+	// FailureRatio opens the breaker once at least this share of the calls in Window failed;
+	// zero disables the breaker
+	FailureRatio float64
+	// Window is how far back calls are counted
+	Window time.Duration
+	// MinRequests is how many calls Window must hold before FailureRatio applies, so a single
+	// early failure does not open the breaker; zero means 10
+	MinRequests int
+	// Cooldown is how long the breaker stays open before letting a probe through
+	Cooldown time.Duration
+}
+
+// Transition describes one state change
+type Transition struct {
+	From, To State
+	At       time.Time
+	// Failures and Requests are the window counts that opened a closed breaker; zero otherwise
+	Failures, Requests int
+}
+
+type outcome struct {
+	at     time.Time
+	failed bool
+}
+
+// Breaker is a circuit breaker counting outcomes over a sliding window. It is safe for concurrent
+// use; a nil *Breaker lets every call through.
+type Breaker struct {
+	config   Config
+	onChange func(Transition)
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	outcomes []outcome
+	openedAt time.Time
+	probing  bool
+}
+
+// New returns a breaker for config, or nil when config.FailureRatio is zero. onChange, when set,
+// is called after every state change, outside the breaker's lock.
+func New(config Config, onChange func(Transition)) *Breaker {
+	if config.FailureRatio <= 0 {
+		return nil
+	}
+	if config.Window <= 0 {
+		config.Window = 30 * time.Second
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 10
+	}
+	return &Breaker{config: config, onChange: onChange, now: time.Now}
+}
+
+// State returns the current state
+func (b *Breaker) State() State {
+	if b == nil {
+		return Closed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow reports whether a call may proceed. Once the cooldown of an open breaker has passed, the
+// first caller becomes the half-open probe; every call that Allow lets through must end with
+// Record or Abandon.
+func (b *Breaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	var changed *Transition
+	defer func() {
+		b.mu.Unlock()
+		b.notify(changed)
+	}()
+
+	switch b.state {
+	case Open:
+		if b.now().Sub(b.openedAt) < b.config.Cooldown {
+			return ErrOpen
+		}
+		changed = b.moveTo(HalfOpen, 0, 0)
+		b.probing = true
+		return nil
+	case HalfOpen:
+		if b.probing {
+			return ErrOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call
+func (b *Breaker) Record(failed bool) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	var changed *Transition
+	defer func() {
+		b.mu.Unlock()
+		b.notify(changed)
+	}()
+
+	now := b.now()
+	switch b.state {
+	case HalfOpen:
+		b.probing = false
+		b.outcomes = nil
+		if failed {
+			b.openedAt = now
+			changed = b.moveTo(Open, 0, 0)
+		} else {
+			changed = b.moveTo(Closed, 0, 0)
+		}
+	case Closed:
+		b.outcomes = append(b.outcomes, outcome{at: now, failed: failed})
+		failures, requests := b.count(now)
+		if requests >= b.config.MinRequests && float64(failures) >= b.config.FailureRatio*float64(requests) {
+			b.outcomes = nil
+			b.openedAt = now
+			changed = b.moveTo(Open, failures, requests)
+		}
+	}
+}
+
+// Abandon releases a call that ended without an outcome worth counting, such as a cancelled one
+func (b *Breaker) Abandon() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == HalfOpen {
+		b.probing = false
+	}
+}
+
+// count drops outcomes older than the window and counts the rest
+func (b *Breaker) count(now time.Time) (failures, requests int) {
+	cutoff := now.Add(-b.config.Window)
+	keep := b.outcomes[:0]
+	for _, o := range b.outcomes {
+		if o.at.After(cutoff) {
+			keep = append(keep, o)
+			if o.failed {
+				failures++
+			}
+		}
+	}
+	b.outcomes = keep
+	return failures, len(keep)
+}
+
+func (b *Breaker) moveTo(state State, failures, requests int) *Transition {
+	t := &Transition{From: b.state, To: state, At: b.now(), Failures: failures, Requests: requests}
+	b.state = state
+	return t
+}
+
+func (b *Breaker) notify(t *Transition) {
+	if t != nil && b.onChange != nil {
+		b.onChange(*t)
+	}
+}
@@ -0,0 +1,112 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBreaker(t *testing.T) (*Breaker, *fakeClock, *[]Transition) {
+	t.Helper()
+	var transitions []Transition
+	b := New(Config{FailureRatio: 0.5, Window: 10 * time.Second, MinRequests: 4, Cooldown: 5 * time.Second}, func(tr Transition) {
+		transitions = append(transitions, tr)
+	})
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b.now = clock.Now
+	return b, clock, &transitions
+}
+
+func call(b *Breaker, failed bool) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	b.Record(failed)
+	return nil
+}
+
+func TestOpensOnFailureRatio(t *testing.T) {
+	b, _, transitions := newTestBreaker(t)
+
+	require.NoError(t, call(b, true))
+	require.NoError(t, call(b, true))
+	require.NoError(t, call(b, false))
+	require.Equal(t, Closed, b.State(), "too few calls to judge")
+
+	require.NoError(t, call(b, true))
+	require.Equal(t, Open, b.State())
+	require.Len(t, *transitions, 1)
+	require.Equal(t, Transition{From: Closed, To: Open, At: (*transitions)[0].At, Failures: 3, Requests: 4}, (*transitions)[0])
+
+	require.True(t, errors.Is(b.Allow(), ErrOpen))
+}
+
+func TestZeroConfigNeedsMinimumRequests(t *testing.T) {
+	b := New(Config{FailureRatio: 0.5}, nil)
+
+	for i := 0; i < 9; i++ {
+		require.NoError(t, call(b, true))
+	}
+	require.Equal(t, Closed, b.State(), "early failures alone do not open the breaker")
+
+	require.NoError(t, call(b, true))
+	require.Equal(t, Open, b.State())
+}
+
+func TestOldFailuresLeaveTheWindow(t *testing.T) {
+	b, clock, _ := newTestBreaker(t)
+
+	require.NoError(t, call(b, true))
+	require.NoError(t, call(b, true))
+	clock.Advance(11 * time.Second)
+	require.NoError(t, call(b, false))
+	require.NoError(t, call(b, false))
+	require.NoError(t, call(b, true))
+	require.NoError(t, call(b, false))
+	require.Equal(t, Closed, b.State())
+}
+
+func TestHalfOpenProbe(t *testing.T) {
+	b, clock, transitions := newTestBreaker(t)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, call(b, true))
+	}
+	require.Equal(t, Open, b.State())
+
+	clock.Advance(5 * time.Second)
+	require.NoError(t, b.Allow(), "the first call after the cooldown probes")
+	require.Equal(t, HalfOpen, b.State())
+	require.ErrorIs(t, b.Allow(), ErrOpen, "only one probe at a time")
+
+	b.Record(true)
+	require.Equal(t, Open, b.State(), "a failed probe reopens")
+	require.ErrorIs(t, b.Allow(), ErrOpen)
+
+	clock.Advance(5 * time.Second)
+	require.NoError(t, b.Allow())
+	b.Abandon()
+	require.NoError(t, b.Allow(), "an abandoned probe frees the slot")
+	b.Record(false)
+	require.Equal(t, Closed, b.State())
+
+	var states []string
+	for _, tr := range *transitions {
+		states = append(states, tr.To.String())
+	}
+	require.Equal(t, []string{"open", "half-open", "open", "half-open", "closed"}, states)
+}
+
+func TestDisabledBreakerIsNil(t *testing.T) {
+	b := New(Config{}, nil)
+	require.Nil(t, b)
+	require.NoError(t, b.Allow())
+	b.Record(true)
+	require.Equal(t, Closed, b.State())
+}
@@ -0,0 +1,36 @@
+package correlation
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerAddsCorrelationIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil), "run-default"))
+
+	logger.Info("outside run")
+
+	ctx := WithOperationID(WithRunID(context.Background(), "run-1"), "op-1")
+	logger.InfoContext(ctx, "inside operation")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], "run_id=run-default")
+	require.NotContains(t, lines[0], "op_id")
+	require.Contains(t, lines[1], "run_id=run-1")
+	require.Contains(t, lines[1], "op_id=op-1")
+}
+
+func TestRequestID(t *testing.T) {
+	ctx := WithRunID(context.Background(), "run-1")
+	require.Equal(t, "run-1", RequestID(ctx))
+	require.Equal(t, "run-1/op-1", RequestID(WithOperationID(ctx, "op-1")))
+	require.Len(t, NewOperationID(), 12)
+	require.Regexp(t, `^\d{8}T\d{6}Z-[0-9a-f]{8}$`, NewRunID())
+}
@@ -0,0 +1,351 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are histogram upper bounds in seconds, suited to rate limiter waits and HTTP latencies
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Registry holds metric families and renders them in the Prometheus text exposition format.
+// A nil *Registry is valid and hands out no-op metrics, so instrumented code never needs nil checks.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindGauge     kind = "gauge"
+	kindHistogram kind = "histogram"
+)
+
+type family struct {
+	name       string
+	help       string
+	kind       kind
+	labelNames []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+
+	mu      sync.Mutex
+	value   float64
+	bounds  []float64 // bucket upper bounds, histogram only
+	counts  []uint64  // cumulative count per bucket, histogram only
+	sum     float64
+	samples uint64
+}
+
+// register returns the family called name, creating it on first use. Registering the same name
+// twice with the same kind returns the existing family so independent components can share metrics.
+func (r *Registry) register(name, help string, k kind, buckets []float64, labelNames []string) *family {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		if f.kind != k || len(f.labelNames) != len(labelNames) {
+			panic(fmt.Sprintf("metrics: %s re-registered with a different type or labels", name))
+		}
+		return f
+	}
+
+	f := &family{
+		name:       name,
+		help:       help,
+		kind:       k,
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     make(map[string]*series),
+	}
+	r.families[name] = f
+	return f
+}
+
+func (f *family) with(labelValues []string) *series {
+	if f == nil {
+		return nil
+	}
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if f.kind == kindHistogram {
+			s.bounds = f.buckets
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// CounterVec is a family of monotonically increasing counters partitioned by labels
+type CounterVec struct{ f *family }
+
+// Counter is a single labelled counter
+type Counter struct{ s *series }
+
+// Counter registers (or returns) a counter family
+func (r *Registry) Counter(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{f: r.register(name, help, kindCounter, nil, labelNames)}
+}
+
+// With returns the counter for the given label values, in the order the labels were declared
+func (v *CounterVec) With(labelValues ...string) Counter {
+	return Counter{s: v.f.with(labelValues)}
+}
+
+// Inc adds one to the counter
+func (c Counter) Inc() { c.Add(1) }
+
+// Add increases the counter by delta, which must not be negative
+func (c Counter) Add(delta float64) {
+	if c.s == nil || delta < 0 {
+		return
+	}
+	c.s.mu.Lock()
+	c.s.value += delta
+	c.s.mu.Unlock()
+}
+
+// GaugeVec is a family of values that can go up and down, partitioned by labels
+type GaugeVec struct{ f *family }
+
+// Gauge is a single labelled gauge
+type Gauge struct{ s *series }
+
+// Gauge registers (or returns) a gauge family
+func (r *Registry) Gauge(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{f: r.register(name, help, kindGauge, nil, labelNames)}
+}
+
+// With returns the gauge for the given label values
+func (v *GaugeVec) With(labelValues ...string) Gauge {
+	return Gauge{s: v.f.with(labelValues)}
+}
+
+// Add changes the gauge by delta
+func (g Gauge) Add(delta float64) {
+	if g.s == nil {
+		return
+	}
+	g.s.mu.Lock()
+	g.s.value += delta
+	g.s.mu.Unlock()
+}
+
+// Inc adds one to the gauge
+func (g Gauge) Inc() { g.Add(1) }
+
+// Dec subtracts one from the gauge
+func (g Gauge) Dec() { g.Add(-1) }
+
+// Set replaces the gauge value
+func (g Gauge) Set(value float64) {
+	if g.s == nil {
+		return
+	}
+	g.s.mu.Lock()
+	g.s.value = value
+	g.s.mu.Unlock()
+}
+
+// HistogramVec is a family of histograms partitioned by labels
+type HistogramVec struct{ f *family }
+
+// Histogram is a single labelled histogram
+type Histogram struct{ s *series }
+
+// Histogram registers (or returns) a histogram family; nil buckets use DefaultBuckets
+func (r *Registry) Histogram(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &HistogramVec{f: r.register(name, help, kindHistogram, sorted, labelNames)}
+}
+
+// With returns the histogram for the given label values
+func (v *HistogramVec) With(labelValues ...string) Histogram {
+	return Histogram{s: v.f.with(labelValues)}
+}
+
+// Observe records one sample
+func (h Histogram) Observe(value float64) {
+	if h.s == nil {
+		return
+	}
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	h.s.sum += value
+	h.s.samples++
+	for i, bound := range h.s.bounds {
+		if value <= bound {
+			h.s.counts[i]++
+		}
+	}
+}
+
+// WriteText renders every metric in the Prometheus text exposition format (version 0.0.4)
+func (r *Registry) WriteText(w io.Writer) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make([]*family, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		families = append(families, r.families[name])
+	}
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+func (f *family) write(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+
+	f.mu.Lock()
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	series := make([]*series, 0, len(keys))
+	for _, key := range keys {
+		series = append(series, f.series[key])
+	}
+	f.mu.Unlock()
+
+	for _, s := range series {
+		s.mu.Lock()
+		labels := formatLabels(f.labelNames, s.labelValues)
+		switch f.kind {
+		case kindHistogram:
+			for i, bound := range s.bounds {
+				fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, withLabel(labels, "le", formatFloat(bound)), s.counts[i])
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, withLabel(labels, "le", "+Inf"), s.samples)
+			fmt.Fprintf(w, "%s_sum%s %s\n", f.name, labels, formatFloat(s.sum))
+			fmt.Fprintf(w, "%s_count%s %d\n", f.name, labels, s.samples)
+		default:
+			fmt.Fprintf(w, "%s%s %s\n", f.name, labels, formatFloat(s.value))
+		}
+		s.mu.Unlock()
+	}
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelEscaper.Replace(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func withLabel(labels, name, value string) string {
+	pair := name + `="` + labelEscaper.Replace(value) + `"`
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// Handler serves the registry at any path in the Prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := r.WriteText(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// WriteTextfile atomically writes a snapshot of the registry to path, in the format expected
+// by the node exporter's textfile collector
+func (r *Registry) WriteTextfile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create metrics snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := r.WriteText(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metrics snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write metrics snapshot: %w", err)
+	}
+	return nil
+}
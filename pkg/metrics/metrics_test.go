@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTextRendersPrometheusFormat(t *testing.T) {
+	registry := NewRegistry()
+
+	requests := registry.Counter("test_requests_total", "Requests sent.", "method", "status")
+	requests.With("POST", "200").Inc()
+	requests.With("POST", "200").Add(2)
+	requests.With("DELETE", "429").Inc()
+
+	registry.Gauge("test_in_flight", "Requests in flight.").With().Set(3)
+
+	wait := registry.Histogram("test_wait_seconds", "Wait time.", []float64{0.1, 1})
+	wait.With().Observe(0.05)
+	wait.With().Observe(0.5)
+	wait.With().Observe(5)
+
+	var out strings.Builder
+	require.NoError(t, registry.WriteText(&out))
+
+	require.Equal(t, `# HELP test_in_flight Requests in flight.
+# TYPE test_in_flight gauge
+test_in_flight 3
+# HELP test_requests_total Requests sent.
+# TYPE test_requests_total counter
+test_requests_total{method="DELETE",status="429"} 1
+test_requests_total{method="POST",status="200"} 3
+# HELP test_wait_seconds Wait time.
+# TYPE test_wait_seconds histogram
+test_wait_seconds_bucket{le="0.1"} 1
+test_wait_seconds_bucket{le="1"} 2
+test_wait_seconds_bucket{le="+Inf"} 3
+test_wait_seconds_sum 5.55
+test_wait_seconds_count 3
+`, out.String())
+}
+
+func TestRegisteringTwiceSharesTheFamily(t *testing.T) {
+	registry := NewRegistry()
+
+	registry.Counter("shared_total", "Shared.", "component").With("client").Inc()
+	registry.Counter("shared_total", "Shared.", "component").With("client").Inc()
+
+	var out strings.Builder
+	require.NoError(t, registry.WriteText(&out))
+	require.Contains(t, out.String(), `shared_total{component="client"} 2`)
+}
+
+func TestNilRegistryIsNoop(t *testing.T) {
+	var registry *Registry
+
+	registry.Counter("noop_total", "Noop.", "a").With("x").Inc()
+	registry.Gauge("noop", "Noop.").With().Dec()
+	registry.Histogram("noop_seconds", "Noop.", nil).With().Observe(1)
+
+	var out strings.Builder
+	require.NoError(t, registry.WriteText(&out))
+	require.Empty(t, out.String())
+}
+
+func TestWriteTextfile(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("snapshot_total", "Snapshot.").With().Inc()
+
+	path := filepath.Join(t.TempDir(), "megaverse.prom")
+	require.NoError(t, registry.WriteTextfile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "snapshot_total 1\n")
+}
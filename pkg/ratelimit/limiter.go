@@ -10,8 +10,9 @@ import (
 
 // Limiter provides rate limiting functionality
 type Limiter struct {
-	limiter *rate.Limiter
-	mu      sync.Mutex
+	limiter  *rate.Limiter
+	mu       sync.Mutex
+	resumeAt time.Time
 }
 
 // NewLimiter creates a new rate limiter
@@ -21,11 +22,38 @@ func NewLimiter(rps float64) *Limiter {
 	}
 }
 
-// Wait blocks until the limiter permits an event to happen
+// Wait blocks until the limiter permits an event to happen, including any pause in effect
 func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		pause := time.Until(l.resumeAt)
+		l.mu.Unlock()
+		if pause <= 0 {
+			break
+		}
+
+		// The pause may be extended while we sleep, so check again afterwards.
+		timer := time.NewTimer(pause)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
 	return l.limiter.Wait(ctx)
 }
 
+// Pause holds every waiter for d, for example when the server asks clients to back off.
+// Overlapping pauses end at the latest requested time.
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if resumeAt := time.Now().Add(d); resumeAt.After(l.resumeAt) {
+		l.resumeAt = resumeAt
+	}
+}
+
 // Allow reports whether an event may happen now
 func (l *Limiter) Allow() bool {
 	return l.limiter.Allow()
@@ -2,6 +2,7 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	retry "github.com/avast/retry-go/v4"
@@ -49,6 +50,11 @@ func Do(ctx context.Context, fn RetryableFunc, config Config, isRetryable IsRetr
 		retry.Delay(config.InitialDelay),
 		retry.MaxDelay(config.MaxDelay),
 		retry.DelayType(func(n uint, err error, retryConfig *retry.Config) time.Duration {
+			// A delay requested by the server wins over our own schedule; retry-go still caps it at MaxDelay
+			if hint, ok := DelayHint(err); ok {
+				return hint
+			}
+
 			// Exponential backoff with our multiplier
 			delay := config.InitialDelay
 			for i := uint(0); i < n; i++ {
@@ -70,4 +76,31 @@ func Do(ctx context.Context, fn RetryableFunc, config Config, isRetryable IsRetr
 	}
 
 	return retry.Do(retryableFunc, opts...)
-}
\ No newline at end of file
+}
+
+// hintedError carries the delay a server asked for before the next attempt
+type hintedError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *hintedError) Error() string { return e.err.Error() }
+func (e *hintedError) Unwrap() error { return e.err }
+
+// After wraps err with the delay to wait before retrying it, for example from a Retry-After header.
+// Do uses the delay instead of its exponential backoff; non-positive delays leave err unchanged.
+func After(err error, delay time.Duration) error {
+	if err == nil || delay <= 0 {
+		return err
+	}
+	return &hintedError{err: err, delay: delay}
+}
+
+// DelayHint returns the delay attached to err by After
+func DelayHint(err error) (time.Duration, bool) {
+	var hinted *hintedError
+	if errors.As(err, &hinted) {
+		return hinted.delay, true
+	}
+	return 0, false
+}
@@ -3,6 +3,7 @@ package retry
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -52,3 +53,39 @@ func TestDoStopsOnUnrecoverableError(t *testing.T) {
 	require.Equal(t, 1, attempts)
 	require.Contains(t, err.Error(), expectedErr.Error())
 }
+
+func TestDoHonoursDelayHint(t *testing.T) {
+	ctx := context.Background()
+	attempts := 0
+	start := time.Now()
+
+	err := Do(ctx, func(context.Context) error {
+		attempts++
+		if attempts < 2 {
+			return After(errors.New("slow down"), 50*time.Millisecond)
+		}
+		return nil
+	}, Config{
+		MaxAttempts:  3,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     time.Second,
+		Multiplier:   1.0,
+	}, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestDelayHintSurvivesWrapping(t *testing.T) {
+	base := errors.New("busy")
+	err := fmt.Errorf("request failed: %w", After(base, 2*time.Second))
+
+	delay, ok := DelayHint(err)
+	require.True(t, ok)
+	require.Equal(t, 2*time.Second, delay)
+	require.ErrorIs(t, err, base)
+
+	_, ok = DelayHint(base)
+	require.False(t, ok)
+}
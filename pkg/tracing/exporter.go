@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// FileExporter writes each span as one line of OTLP/JSON (an ExportTraceServiceRequest),
+// so the file can be loaded into trace viewers that accept OTLP JSON offline.
+type FileExporter struct {
+	service string
+
+	mu sync.Mutex
+	w  io.Writer
+	c  io.Closer
+}
+
+// NewFileExporter creates (or truncates) path and exports spans into it
+func NewFileExporter(path, service string) (*FileExporter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace file: %w", err)
+	}
+	return &FileExporter{service: service, w: file, c: file}, nil
+}
+
+// NewWriterExporter exports spans to w
+func NewWriterExporter(w io.Writer, service string) *FileExporter {
+	return &FileExporter{service: service, w: w}
+}
+
+// Export writes span as a single JSON line
+func (e *FileExporter) Export(span *Span) error {
+	line, err := json.Marshal(e.request(span))
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(line)
+	return err
+}
+
+// Close closes the underlying file, if the exporter owns one
+func (e *FileExporter) Close() error {
+	if e.c == nil {
+		return nil
+	}
+	return e.c.Close()
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+// OTLP status codes
+const (
+	statusOK    = 1
+	statusError = 2
+)
+
+func (e *FileExporter) request(span *Span) otlpRequest {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+
+	status := otlpStatus{Code: statusOK}
+	if span.Err != nil {
+		status = otlpStatus{Code: statusError, Message: span.Err.Error()}
+	}
+
+	attrs := make([]otlpKeyValue, 0, len(span.Attributes))
+	for _, attr := range span.Attributes {
+		attrs = append(attrs, keyValue(attr))
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpKeyValue{keyValue(String("service.name", e.service))}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: e.service},
+			Spans: []otlpSpan{{
+				TraceID:           span.TraceID,
+				SpanID:            span.SpanID,
+				ParentSpanID:      span.ParentSpanID,
+				Name:              span.Name,
+				Kind:              int(span.Kind),
+				StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+				EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+				Attributes:        attrs,
+				Status:            status,
+			}},
+		}},
+	}}}
+}
+
+func keyValue(attr Attribute) otlpKeyValue {
+	var value otlpValue
+	switch v := attr.Value.(type) {
+	case string:
+		value.StringValue = &v
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		value.IntValue = &s
+	case float64:
+		value.DoubleValue = &v
+	case bool:
+		value.BoolValue = &v
+	default:
+		s := fmt.Sprint(v)
+		value.StringValue = &s
+	}
+	return otlpKeyValue{Key: attr.Key, Value: value}
+}
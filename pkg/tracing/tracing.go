@@ -0,0 +1,160 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SpanKind mirrors the OTLP span kinds used by this package
+type SpanKind int
+
+const (
+	// KindInternal marks work done inside the process
+	KindInternal SpanKind = 1
+
+	// KindClient marks an outgoing request to a remote service
+	KindClient SpanKind = 3
+)
+
+// Attribute is a key/value pair attached to a span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+// Int returns an integer attribute
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: int64(value)} }
+
+// Float returns a floating point attribute
+func Float(key string, value float64) Attribute { return Attribute{Key: key, Value: value} }
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// Exporter receives finished spans
+type Exporter interface {
+	Export(span *Span) error
+}
+
+// Tracer creates spans and hands them to an exporter when they end.
+// A nil *Tracer is valid and produces no-op spans.
+type Tracer struct {
+	service  string
+	exporter Exporter
+	onError  func(error)
+}
+
+// NewTracer creates a tracer that reports spans for service to exporter.
+// onError, when set, is called if exporting a span fails.
+func NewTracer(service string, exporter Exporter, onError func(error)) *Tracer {
+	return &Tracer{service: service, exporter: exporter, onError: onError}
+}
+
+// Span is a timed operation within a trace
+type Span struct {
+	tracer *Tracer
+
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Kind         SpanKind
+	Start        time.Time
+	End          time.Time
+	Attributes   []Attribute
+	Err          error
+
+	mu    sync.Mutex
+	ended bool
+}
+
+type spanKey struct{}
+
+// SpanFromContext returns the active span, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Start begins a span as a child of the span in ctx, if any, and returns a context carrying it
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, *Span) {
+	if t == nil || t.exporter == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		tracer:     t,
+		SpanID:     newID(8),
+		Name:       name,
+		Kind:       kind,
+		Start:      time.Now(),
+		Attributes: attrs,
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentSpanID = parent.SpanID
+	} else {
+		span.TraceID = newID(16)
+	}
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attributes = append(s.Attributes, attrs...)
+}
+
+// RecordError marks the span as failed
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Err = err
+}
+
+// Finish ends the span and exports it. Only the first call has any effect.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	s.mu.Unlock()
+
+	if err := s.tracer.exporter.Export(s); err != nil && s.tracer.onError != nil {
+		s.tracer.onError(fmt.Errorf("failed to export span %s: %w", s.Name, err))
+	}
+}
+
+func newID(size int) string {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to the clock so IDs stay non-zero.
+		now := time.Now().UnixNano()
+		for i := range buf {
+			buf[i] = byte(now >> (8 * (i % 8)))
+		}
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpansExportAsOTLPJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewTracer("megaverse", NewWriterExporter(&buf, "megaverse"), nil)
+
+	ctx, parent := tracer.Start(context.Background(), "strategy.run", KindInternal, String("strategy", "cross"))
+	_, child := tracer.Start(ctx, "http.attempt", KindClient, Int("http.status_code", 429))
+	child.RecordError(errors.New("too many requests"))
+	child.Finish()
+	parent.Finish()
+	parent.Finish() // a second Finish must not export twice
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var exported []otlpSpan
+	for _, line := range lines {
+		var req otlpRequest
+		require.NoError(t, json.Unmarshal([]byte(line), &req))
+		require.Equal(t, "service.name", req.ResourceSpans[0].Resource.Attributes[0].Key)
+		exported = append(exported, req.ResourceSpans[0].ScopeSpans[0].Spans[0])
+	}
+
+	childSpan, parentSpan := exported[0], exported[1]
+	require.Equal(t, parentSpan.TraceID, childSpan.TraceID)
+	require.Equal(t, parentSpan.SpanID, childSpan.ParentSpanID)
+	require.Len(t, parentSpan.TraceID, 32)
+	require.Len(t, childSpan.SpanID, 16)
+
+	require.Equal(t, statusError, childSpan.Status.Code)
+	require.Equal(t, "429", *childSpan.Attributes[0].Value.IntValue)
+	require.Equal(t, statusOK, parentSpan.Status.Code)
+	require.Equal(t, "cross", *parentSpan.Attributes[0].Value.StringValue)
+}
+
+func TestNilTracerIsNoop(t *testing.T) {
+	var tracer *Tracer
+
+	ctx, span := tracer.Start(context.Background(), "noop", KindInternal)
+	span.SetAttributes(Bool("ignored", true))
+	span.RecordError(errors.New("ignored"))
+	span.Finish()
+
+	require.Nil(t, span)
+	require.Nil(t, SpanFromContext(ctx))
+}